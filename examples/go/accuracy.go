@@ -0,0 +1,39 @@
+// CSV2GEO API - Go Geocoding Example: accuracy levels
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Accuracy is how precisely a result pins down the queried address
+type Accuracy string
+
+const (
+	AccuracyRooftop      Accuracy = "rooftop"            // exact building or parcel
+	AccuracyInterpolated Accuracy = "range_interpolated" // estimated along a street segment
+	AccuracyCenter       Accuracy = "geometric_center"   // center of a street, postcode or area
+	AccuracyApproximate  Accuracy = "approximate"        // city or region level
+	AccuracyUnknown      Accuracy = "unknown"            // missing or not recognized
+)
+
+// ParseAccuracy maps an API accuracy string to an Accuracy, ignoring case
+// and surrounding space. Unrecognized values give AccuracyUnknown.
+func ParseAccuracy(s string) Accuracy {
+	switch a := Accuracy(strings.ToLower(strings.TrimSpace(s))); a {
+	case AccuracyRooftop, AccuracyInterpolated, AccuracyCenter, AccuracyApproximate:
+		return a
+	}
+	return AccuracyUnknown
+}
+
+// UnmarshalJSON decodes an accuracy string through ParseAccuracy, so
+// values added by the server later decode as AccuracyUnknown
+func (a *Accuracy) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*a = ParseAccuracy(s)
+	return nil
+}
@@ -0,0 +1,93 @@
+// CSV2GEO API - Go Geocoding Example: adaptive batch sizing
+package main
+
+import "time"
+
+// Tuning constants for WithAdaptiveBatch
+const (
+	adaptiveTargetLatency = 2 * time.Second // chunk round trip the tuner aims for
+	adaptiveMaxErrorRate  = 0.1             // share of failed items that counts as trouble
+)
+
+// WithAdaptiveBatch makes BatchGeocode and BatchReverseGeocode adjust the
+// chunk size between min and max instead of always using the batch size.
+// Each call starts at the batch size (see WithBatchSize), clamped to
+// [min, max], and after every chunk:
+//
+//   - if the chunk failed, more than 10% of its items failed, or its round
+//     trip took longer than 2s, the size is halved;
+//   - if it took less than 1s, the size grows by a quarter (at least 1);
+//   - otherwise it is kept.
+//
+// Growth is multiplicative too, but gentler than the backoff: 25% per
+// fast chunk against halving on trouble, so the size climbs steadily while
+// the server keeps up and drops quickly once it struggles. State is per
+// call; invalid bounds (min < 1 or max < min) turn adaptive sizing off.
+func WithAdaptiveBatch(min, max int) Option {
+	return func(c *Client) {
+		if min < 1 || max < min {
+			c.adaptive = nil
+			return
+		}
+		c.adaptive = &adaptiveBatch{min: min, max: max}
+	}
+}
+
+// adaptiveBatch holds the WithAdaptiveBatch bounds
+type adaptiveBatch struct {
+	min, max int
+}
+
+// batchTuner picks the size of each chunk of one batch call. Without
+// adaptive bounds it always returns the fixed size.
+type batchTuner struct {
+	size     int
+	min, max int
+	adaptive bool
+	now      func() time.Time
+}
+
+// newBatchTuner starts a tuner at the client's batch size
+func (c *Client) newBatchTuner() *batchTuner {
+	size := c.batchSize
+	if size < 1 {
+		size = defaultBatchSize
+	}
+	t := &batchTuner{size: size, min: size, max: size, now: c.clock.Now}
+	if c.adaptive != nil {
+		t.adaptive = true
+		t.min, t.max = c.adaptive.min, c.adaptive.max
+		t.size = clampInt(size, t.min, t.max)
+	}
+	return t
+}
+
+// observe adjusts the size after a chunk of items took latency, failed
+// items of them failed and err is the error of the whole chunk, if any
+func (t *batchTuner) observe(latency time.Duration, items, failed int, err error) {
+	if !t.adaptive {
+		return
+	}
+	switch {
+	case err != nil || float64(failed) > adaptiveMaxErrorRate*float64(items) || latency > adaptiveTargetLatency:
+		t.size /= 2
+	case latency < adaptiveTargetLatency/2:
+		grow := t.size / 4
+		if grow < 1 {
+			grow = 1
+		}
+		t.size += grow
+	}
+	t.size = clampInt(t.size, t.min, t.max)
+}
+
+// clampInt limits n to [lo, hi]
+func clampInt(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
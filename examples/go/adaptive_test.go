@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBatchTunerSizes(t *testing.T) {
+	c := NewClient(testKey, WithBatchSize(100), WithAdaptiveBatch(10, 200))
+	tuner := c.newBatchTuner()
+
+	steps := []struct {
+		latency time.Duration
+		failed  int
+		err     error
+		want    int
+	}{
+		{500 * time.Millisecond, 0, nil, 125}, // fast: grow by a quarter
+		{500 * time.Millisecond, 0, nil, 156},
+		{1500 * time.Millisecond, 0, nil, 156}, // within target: keep
+		{3 * time.Second, 0, nil, 78},          // slow: halve
+		{500 * time.Millisecond, 20, nil, 39},  // too many item errors: halve
+		{0, 0, errors.New("HTTP 503"), 19},     // failed chunk: halve
+		{0, 0, errors.New("HTTP 503"), 10},     // clamped to min
+		{100 * time.Millisecond, 0, nil, 12},
+	}
+	for i, s := range steps {
+		tuner.observe(s.latency, tuner.size, s.failed, s.err)
+		if tuner.size != s.want {
+			t.Fatalf("step %d: size = %d, want %d", i, tuner.size, s.want)
+		}
+	}
+}
+
+func TestBatchTunerFixedWithoutAdaptive(t *testing.T) {
+	tuner := NewClient(testKey, WithBatchSize(50)).newBatchTuner()
+	tuner.observe(10*time.Second, 50, 50, errors.New("HTTP 503"))
+	if tuner.size != 50 {
+		t.Errorf("size = %d, want fixed 50", tuner.size)
+	}
+}
@@ -0,0 +1,74 @@
+// CSV2GEO API - Go Geocoding Example: authentication schemes
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// AuthMode selects how the API key is sent with each request
+type AuthMode int
+
+const (
+	// AuthBearer sends "Authorization: Bearer <key>". This is the default:
+	// headers are not written to access logs, proxies or browser history
+	// the way URLs are.
+	AuthBearer AuthMode = iota
+	// AuthHeader sends the key in an X-API-Key header, for gateways that
+	// expect it there. It has the same safety properties as AuthBearer.
+	AuthHeader
+	// AuthQuery sends the key as the api_key query parameter, for legacy
+	// endpoints that only accept it there. The key then appears in every
+	// URL, so anything that logs URLs can leak it; the client's own
+	// Logger and error messages redact it, but servers and proxies won't.
+	AuthQuery
+)
+
+// authModeNames are the text forms of the AuthModes, for config files
+var authModeNames = map[AuthMode]string{
+	AuthBearer: "bearer",
+	AuthHeader: "header",
+	AuthQuery:  "query",
+}
+
+// MarshalText returns "bearer", "header" or "query"
+func (m AuthMode) MarshalText() ([]byte, error) {
+	name, ok := authModeNames[m]
+	if !ok {
+		return nil, fmt.Errorf("unknown auth mode %d", int(m))
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText accepts the names written by MarshalText
+func (m *AuthMode) UnmarshalText(text []byte) error {
+	for mode, name := range authModeNames {
+		if string(text) == name {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown auth mode %q: want bearer, header or query", text)
+}
+
+// WithAuthMode chooses how the API key is sent; see AuthMode
+func WithAuthMode(m AuthMode) Option {
+	return func(c *Client) {
+		c.authMode = m
+	}
+}
+
+// applyAuth adds the API key to req according to the client's AuthMode,
+// unless the call replaced the header with WithReservedHeader
+func (c *Client) applyAuth(req *http.Request) {
+	switch c.authMode {
+	case AuthQuery:
+		q := req.URL.Query()
+		q.Set("api_key", c.APIKey)
+		req.URL.RawQuery = q.Encode()
+	case AuthHeader:
+		setIfAbsent(req.Header, "X-API-Key", c.APIKey)
+	default:
+		setIfAbsent(req.Header, "Authorization", "Bearer "+c.APIKey)
+	}
+}
@@ -0,0 +1,114 @@
+// CSV2GEO API - Go Geocoding Example: type-ahead suggestions and place lookup
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Suggestion is one autocomplete match. PlaceID and Location are only set
+// when the server provides them.
+type Suggestion struct {
+	Text      string    `json:"text"`
+	Highlight string    `json:"highlight,omitempty"` // Text with the matched part in <b> tags
+	PlaceID   string    `json:"place_id,omitempty"`
+	Location  *Location `json:"location,omitempty"`
+}
+
+// autocompleteResponse mirrors AutocompleteResponse in openapi.yaml
+type autocompleteResponse struct {
+	Query       string       `json:"query"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// maxAutocompleteLimit is the most suggestions /autocomplete returns.
+const maxAutocompleteLimit = 10
+
+// Autocomplete suggests addresses for partial input using the default
+// client
+func Autocomplete(ctx context.Context, partial string, opts ...CallOption) ([]Suggestion, error) {
+	return defaultClient.Autocomplete(ctx, partial, opts...)
+}
+
+// Autocomplete returns address suggestions for partial, as typed into a
+// search box, from the /autocomplete endpoint. It is cheaper than a full
+// geocode and meant to be called per keystroke. WithCountry biases the
+// suggestions and WithLimit caps them, at most 10 here rather than the
+// 50 geocoding allows; a higher limit fails before any request is made.
+// Empty input fails with ErrEmptyQuery without making a request.
+func (c *Client) Autocomplete(ctx context.Context, partial string, opts ...CallOption) ([]Suggestion, error) {
+	if strings.TrimSpace(partial) == "" {
+		return nil, ErrEmptyQuery
+	}
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if limit, _ := strconv.Atoi(cfg.params.Get("limit")); limit > maxAutocompleteLimit {
+		return nil, fmt.Errorf("invalid limit %d: autocomplete returns at most %d suggestions", limit, maxAutocompleteLimit)
+	}
+	cfg.params.Add("q", partial)
+
+	var resp autocompleteResponse
+	if err := c.getJSON(ctx, cfg, "/autocomplete", "autocomplete failed", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Suggestions, nil
+}
+
+// placeDetail is the part of PlaceDetailResponse in openapi.yaml that maps
+// onto a GeocodeResult
+type placeDetail struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Location   Location `json:"location"`
+	Address    string   `json:"address"`
+	City       string   `json:"city"`
+	State      string   `json:"state"`
+	Postcode   string   `json:"postcode"`
+	Country    string   `json:"country"`
+	Confidence float64  `json:"confidence"`
+}
+
+// GeocodeByPlaceID looks up a place by id using the default client
+func GeocodeByPlaceID(ctx context.Context, placeID string) (*GeocodeResult, error) {
+	return defaultClient.GeocodeByPlaceID(ctx, placeID)
+}
+
+// GeocodeByPlaceID resolves a Suggestion's PlaceID to a full result, to
+// finish an autocomplete flow. It uses the place details endpoint,
+// /places/by-id/{id}. The place's address becomes FormattedAddress (its
+// name if it has no address) and its Accuracy is AccuracyUnknown, since
+// the endpoint does not report one. An unknown id gives ErrNoResults.
+func (c *Client) GeocodeByPlaceID(ctx context.Context, placeID string) (*GeocodeResult, error) {
+	if strings.TrimSpace(placeID) == "" {
+		return nil, ErrEmptyQuery
+	}
+	cfg, _ := newCallConfig(nil)
+	var place placeDetail
+	if err := c.getJSON(ctx, cfg, "/places/by-id/"+url.PathEscape(placeID), "place lookup failed", &place); err != nil {
+		if IsNotFound(err) {
+			return nil, fmt.Errorf("place %q: %w", placeID, ErrNoResults)
+		}
+		return nil, err
+	}
+	formatted := place.Address
+	if formatted == "" {
+		formatted = place.Name
+	}
+	return &GeocodeResult{
+		FormattedAddress: formatted,
+		Location:         place.Location,
+		Accuracy:         AccuracyUnknown,
+		Confidence:       place.Confidence,
+		Components: AddressComponents{
+			City:     place.City,
+			State:    place.State,
+			Postcode: place.Postcode,
+			Country:  place.Country,
+		},
+	}, nil
+}
@@ -0,0 +1,218 @@
+// CSV2GEO API - Go Geocoding Example: concurrent batch geocoding
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// BatchResultItem is the outcome for one address of a concurrent batch.
+// Exactly one of Response and Err is set.
+type BatchResultItem struct {
+	Index    int
+	Query    string
+	Response *GeocodeResponse
+	Err      error
+}
+
+// BatchGeocodeConcurrent geocodes addresses concurrently using the default client
+func BatchGeocodeConcurrent(ctx context.Context, addresses []string, concurrency int, opts ...CallOption) ([]BatchResultItem, error) {
+	return defaultClient.BatchGeocodeConcurrent(ctx, addresses, concurrency, opts...)
+}
+
+// BatchGeocodeConcurrent geocodes each address with its own request, spread
+// across concurrency workers, and returns one item per address in input
+// order. Per-address failures are reported in the item, not as the returned
+// error. Once ctx is cancelled no new requests are started: the remaining
+// items get ctx.Err() and ctx.Err() is also returned. opts are passed to
+// every request; WithProgress is called as each address completes.
+//
+// With WithFailFast the first per-address failure stops the batch instead:
+// requests in flight are cancelled, the failure is returned as a
+// *BatchItemError, and every address that did not complete gets
+// context.Canceled.
+//
+// With WithDedup, addresses that normalize to the same string are geocoded
+// once and the result is copied to each of their positions.
+func (c *Client) BatchGeocodeConcurrent(ctx context.Context, addresses []string, concurrency int, opts ...CallOption) ([]BatchResultItem, error) {
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	geocode := func(ctx context.Context, i int) (*GeocodeResponse, error) {
+		return c.GeocodeContext(ctx, addresses[i], opts...)
+	}
+	return runConcurrent(ctx, cfg, addresses, concurrency, geocode)
+}
+
+// BatchReverseGeocodeConcurrent reverse geocodes points concurrently using
+// the default client
+func BatchReverseGeocodeConcurrent(ctx context.Context, points []Location, concurrency int, opts ...CallOption) ([]BatchResultItem, error) {
+	return defaultClient.BatchReverseGeocodeConcurrent(ctx, points, concurrency, opts...)
+}
+
+// BatchReverseGeocodeConcurrent is BatchGeocodeConcurrent for points: each
+// is reverse geocoded with its own request, and the items come back in
+// input order with the point as "lat,lng" in Query. An invalid point
+// fails only its own item, with ErrInvalidCoordinate. WithRateLimit paces
+// the requests across all workers; cancellation, WithFailFast and
+// WithDedup work as for BatchGeocodeConcurrent, deduplicating identical
+// points.
+func (c *Client) BatchReverseGeocodeConcurrent(ctx context.Context, points []Location, concurrency int, opts ...CallOption) ([]BatchResultItem, error) {
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]string, len(points))
+	for i, p := range points {
+		queries[i] = pointQuery(p)
+	}
+	geocode := func(ctx context.Context, i int) (*GeocodeResponse, error) {
+		return c.ReverseGeocodeContext(ctx, points[i].Lat, points[i].Lng, opts...)
+	}
+	return runConcurrent(ctx, cfg, queries, concurrency, geocode)
+}
+
+// runConcurrent implements the concurrent batch methods. queries are the
+// items' Query values, and geocode fetches the response for position i.
+func runConcurrent(ctx context.Context, cfg *callConfig, queries []string, concurrency int,
+	geocode func(ctx context.Context, i int) (*GeocodeResponse, error)) ([]BatchResultItem, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	progress := newProgress(cfg.progress, len(queries))
+	items := make([]BatchResultItem, len(queries))
+	for i, q := range queries {
+		items[i] = BatchResultItem{Index: i, Query: q}
+	}
+	groups := groupAddresses(queries, cfg)
+
+	parent := ctx
+	var failed failFast
+	if cfg.failFast {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		failed.cancel = cancel
+	}
+
+	jobs := make(chan []int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range jobs {
+				first := group[0]
+				resp, err := geocode(ctx, first)
+				for n, i := range group {
+					if resp != nil && n > 0 {
+						items[i].Response = cloneResponse(resp)
+					} else {
+						items[i].Response = resp
+					}
+					items[i].Err = err
+					progress.step()
+				}
+				if err != nil && cfg.failFast {
+					failed.record(first, items[first].Query, err)
+				}
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(groups); next++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- groups[next]:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for _, group := range groups[next:] {
+			for _, i := range group {
+				items[i].Err = err
+			}
+		}
+		if parent.Err() == nil && failed.err != nil {
+			return items, failed.err
+		}
+		return items, err
+	}
+	return items, nil
+}
+
+// BatchItemError is the failure that stopped a concurrent batch made with
+// WithFailFast
+type BatchItemError struct {
+	Index int
+	Query string
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch item %d (%q) failed: %v", e.Index, e.Query, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error { return e.Err }
+
+// failFast keeps the first failure of a WithFailFast batch and cancels the
+// rest of it
+type failFast struct {
+	mu     sync.Mutex
+	err    *BatchItemError
+	cancel context.CancelFunc
+}
+
+// record keeps a failure unless one came first. Failures after the cancel
+// are its consequence, not its cause.
+func (f *failFast) record(index int, query string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return
+	}
+	f.err = &BatchItemError{Index: index, Query: query, Err: err}
+	f.cancel()
+}
+
+// groupAddresses returns the input positions to geocode together. Without
+// WithDedup every address is its own group; with it, addresses sharing a
+// normalized form are grouped, in order of first appearance.
+func groupAddresses(addresses []string, cfg *callConfig) [][]int {
+	groups := make([][]int, 0, len(addresses))
+	if !cfg.dedup {
+		for i := range addresses {
+			groups = append(groups, []int{i})
+		}
+		return groups
+	}
+	normalize := cfg.normalizer
+	if normalize == nil {
+		normalize = dedupKey
+	}
+	byKey := make(map[string]int)
+	for i, addr := range addresses {
+		key := normalize(addr)
+		if g, ok := byKey[key]; ok {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+		byKey[key] = len(groups)
+		groups = append(groups, []int{i})
+	}
+	return groups
+}
+
+// dedupKey is the default WithDedup normalization: NormalizeAddress,
+// compared case-insensitively
+func dedupKey(s string) string {
+	return strings.ToLower(NormalizeAddress(s))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBatchGeocodeConcurrentFailFast(t *testing.T) {
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") == "bad" {
+			RespondError(http.StatusBadRequest, "invalid_query", "bad address")(w, r)
+			return
+		}
+		// Hold every other address until the client gives up on it
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	addresses := []string{"slow 1", "bad", "slow 2", "slow 3", "slow 4"}
+	start := time.Now()
+	items, err := c.BatchGeocodeConcurrent(context.Background(), addresses, 2, WithFailFast())
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("returned after %v, want promptly", elapsed)
+	}
+	var itemErr *BatchItemError
+	if !errors.As(err, &itemErr) || itemErr.Index != 1 || apiStatus(err) != http.StatusBadRequest {
+		t.Fatalf("err = %v, want *BatchItemError for index 1 wrapping the 400", err)
+	}
+	for i, item := range items {
+		if i != 1 && !errors.Is(item.Err, context.Canceled) {
+			t.Errorf("items[%d].Err = %v, want context.Canceled", i, item.Err)
+		}
+	}
+}
+
+func TestBatchReverseGeocodeConcurrent(t *testing.T) {
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		RespondGeocode(GeocodeResult{FormattedAddress: q.Get("lat") + " " + q.Get("lng")})(w, r)
+	}))
+	defer srv.Close()
+
+	points := []Location{{Lat: 38.8977, Lng: -77.0365}, {Lat: 91, Lng: 0}, {Lat: 51.5034, Lng: -0.1276}}
+	items, err := c.BatchReverseGeocodeConcurrent(context.Background(), points, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"38.897700 -77.036500", "", "51.503400 -0.127600"}
+	for i, item := range items {
+		if item.Index != i || item.Query != pointQuery(points[i]) {
+			t.Errorf("items[%d] = index %d, query %q", i, item.Index, item.Query)
+		}
+		if i == 1 {
+			if !errors.Is(item.Err, ErrInvalidCoordinate) {
+				t.Errorf("items[1].Err = %v, want ErrInvalidCoordinate", item.Err)
+			}
+			continue
+		}
+		if item.Err != nil || item.Response.Results[0].FormattedAddress != want[i] {
+			t.Errorf("items[%d] = %+v, %v; want %q", i, item.Response, item.Err, want[i])
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// CSV2GEO API - Go Geocoding Example: circuit breaker
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without making a request, while the circuit
+// breaker set up by WithCircuitBreaker is open
+var ErrCircuitOpen = errors.New("csv2geo: circuit breaker open")
+
+// CircuitState is the state of a client's circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // requests flow normally
+	CircuitOpen     CircuitState = "open"      // requests fail fast with ErrCircuitOpen
+	CircuitHalfOpen CircuitState = "half-open" // one trial request is allowed through
+)
+
+// WithCircuitBreaker stops sending requests after failureThreshold
+// consecutive failures; until cooldown has elapsed every call fails
+// immediately with ErrCircuitOpen. After the cooldown a single trial
+// request is let through: success closes the circuit, failure opens it for
+// another cooldown. A failure is a transport error or a 5xx or 429
+// response that is still failing after retries; other 4xx responses mean
+// the API is up and count as successes, and cancelled requests are not
+// counted. A failureThreshold below 1 disables the breaker.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		if failureThreshold < 1 {
+			c.breaker = nil
+			return
+		}
+		c.breaker = &circuitBreaker{threshold: failureThreshold, cooldown: cooldown}
+	}
+}
+
+// CircuitState reports the state of the client's circuit breaker. Clients
+// without one are always CircuitClosed.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.State(c.clock.Now())
+}
+
+// circuitBreaker counts consecutive failures. It is safe for concurrent
+// use; trial marks the half-open request in flight so that concurrent
+// callers keep failing fast until it finishes.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	open      bool
+	trial     bool
+}
+
+// State reports the current state at now; an open circuit whose cooldown
+// has elapsed is half-open
+func (b *circuitBreaker) State(now time.Time) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state(now)
+}
+
+func (b *circuitBreaker) state(now time.Time) CircuitState {
+	switch {
+	case !b.open:
+		return CircuitClosed
+	case b.trial || now.Sub(b.openedAt) >= b.cooldown:
+		return CircuitHalfOpen
+	default:
+		return CircuitOpen
+	}
+}
+
+// allow reports ErrCircuitOpen unless a request may be sent. It returns
+// true for the half-open trial request, which must be ended with done.
+func (b *circuitBreaker) allow(now time.Time) (trial bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state(now) {
+	case CircuitClosed:
+		return false, nil
+	case CircuitHalfOpen:
+		if b.trial {
+			return false, ErrCircuitOpen
+		}
+		b.trial = true
+		return true, nil
+	default:
+		return false, ErrCircuitOpen
+	}
+}
+
+// done records the outcome at now of a request let through by allow. ok is nil
+// when the outcome says nothing about the API, e.g. on cancellation.
+func (b *circuitBreaker) done(now time.Time, trial bool, ok *bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if trial {
+		b.trial = false
+	}
+	switch {
+	case ok == nil:
+	case *ok:
+		b.failures = 0
+		b.open = false
+	default:
+		b.failures++
+		if trial || b.failures >= b.threshold {
+			b.open = true
+			b.openedAt = now
+		}
+	}
+}
+
+// breakerOutcome classifies the result of send for the circuit breaker
+func breakerOutcome(req *http.Request, resp *http.Response, err error) *bool {
+	var ok bool
+	switch {
+	case err != nil:
+		if req.Context().Err() != nil || errors.Is(err, context.Canceled) {
+			return nil
+		}
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+	default:
+		ok = true
+	}
+	return &ok
+}
@@ -0,0 +1,185 @@
+// CSV2GEO API - Go Geocoding Example: response caching
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores geocode responses by key. Implementations must be safe for
+// concurrent use; a Redis- or memcached-backed one only needs these two
+// methods. A ttl of 0 means the entry does not expire.
+type Cache interface {
+	Get(key string) (*GeocodeResponse, bool)
+	Set(key string, resp *GeocodeResponse, ttl time.Duration)
+}
+
+// WithCache serves repeated forward, structured and reverse geocodes from
+// cache, storing successful responses for ttl
+func WithCache(cache Cache, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cache = cache
+		c.cacheTTL = ttl
+	}
+}
+
+// cacheKey identifies a request by endpoint, parameters and extra
+// headers. The endpoint is the full URL, base and API version included, so
+// clients pointed at different servers or versions can share a cache
+// without mixing their answers. The q parameter is normalized so trivially different spellings
+// share an entry; the API key is never part of the key. Headers from
+// WithHeader, WithReservedHeader and WithDefaultHeader, such as a tenant
+// ID, can change the answer, so requests that differ in them get
+// different entries; they enter the key only as a hash, keeping tokens out
+// of an external cache.
+func (c *Client) cacheKey(path string, cfg *callConfig) string {
+	params := make(url.Values, len(cfg.params))
+	for k, vs := range cfg.params {
+		if k == "api_key" {
+			continue
+		}
+		params[k] = vs
+	}
+	if q, ok := params["q"]; ok && len(q) > 0 {
+		params["q"] = []string{strings.ToLower(NormalizeAddress(q[0]))}
+	}
+	key := c.endpoint(path) + "?" + params.Encode()
+	if h := headerDigest(c.header, cfg.header); h != "" {
+		key += "#h=" + h
+	}
+	return key
+}
+
+// headerDigest hashes the headers a request is sent with beyond the
+// client's own: defaults, overridden by call headers of the same name. It
+// is "" when there are none.
+func headerDigest(defaults, call http.Header) string {
+	merged := make(http.Header, len(defaults)+len(call))
+	for k, vs := range defaults {
+		merged[k] = vs
+	}
+	for k, vs := range call {
+		merged[k] = vs
+	}
+	if len(merged) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(merged))
+	for k := range merged {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, k := range names {
+		fmt.Fprintf(h, "%s:%q\n", k, merged[k])
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// cloneResponse copies resp so callers can't modify a cached entry
+func cloneResponse(resp *GeocodeResponse) *GeocodeResponse {
+	clone := *resp
+	clone.Results = append([]GeocodeResult(nil), resp.Results...)
+	return &clone
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entry
+// once it holds capacity entries. TTLs are measured on the clock of the
+// first Client it is installed on with WithCache.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is most recently used
+	entries  map[string]*list.Element
+	clock    clock // nil until installed on a Client
+}
+
+// useClock makes l measure TTLs on clk, unless a client already set one
+func (l *LRUCache) useClock(clk clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.clock == nil {
+		l.clock = clk
+	}
+}
+
+// now reads l's clock, the wall clock if none was set. l.mu must be held.
+func (l *LRUCache) now() time.Time {
+	if l.clock == nil {
+		return time.Now()
+	}
+	return l.clock.Now()
+}
+
+type lruEntry struct {
+	key     string
+	resp    *GeocodeResponse
+	expires time.Time // zero means never
+}
+
+// NewLRUCache creates an LRUCache holding at most capacity entries
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry for key if present and not expired
+func (l *LRUCache) Get(key string) (*GeocodeResponse, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && l.now().After(e.expires) {
+		l.order.Remove(el)
+		delete(l.entries, key)
+		return nil, false
+	}
+	l.order.MoveToFront(el)
+	return e.resp, true
+}
+
+// Set stores resp under key for ttl
+func (l *LRUCache) Set(key string, resp *GeocodeResponse, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var expires time.Time
+	if ttl > 0 {
+		expires = l.now().Add(ttl)
+	}
+	if el, ok := l.entries[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.resp, e.expires = resp, expires
+		l.order.MoveToFront(el)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, resp: resp, expires: expires})
+	for l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet evicted
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
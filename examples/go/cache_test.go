@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheTTLUsesClientClock(t *testing.T) {
+	clk := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	calls := 0
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		RespondGeocode()(w, r)
+	}), WithCache(NewLRUCache(10), time.Minute), withClock(clk))
+	defer srv.Close()
+
+	for _, advance := range []time.Duration{0, 30 * time.Second, 31 * time.Second} {
+		clk.now = clk.now.Add(advance)
+		if _, err := c.Geocode("1600 Pennsylvania Ave"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("%d requests, want 2: a hit at 30s and a miss once the minute is up", calls)
+	}
+}
+
+func TestTimezoneKeptOutOfGeocodeCache(t *testing.T) {
+	calls := 0
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		RespondJSON(http.StatusOK, map[string]interface{}{
+			"timezone": map[string]string{"id": "America/New_York"},
+		})(w, r)
+	}), WithCache(NewLRUCache(10), 0))
+	defer srv.Close()
+
+	cache := c.cache.(*LRUCache)
+	for _, loc := range []Location{{Lat: 38.89771, Lng: -77.03641}, {Lat: 38.89768, Lng: -77.03612}} {
+		tz, err := c.Timezone(context.Background(), loc)
+		if err != nil || tz != "America/New_York" {
+			t.Fatalf("Timezone(%v) = %q, %v", loc, tz, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("%d requests, want 1: nearby points share an entry", calls)
+	}
+	if n := cache.Len(); n != 0 {
+		t.Errorf("geocode cache holds %d entries, want none from Timezone", n)
+	}
+}
+
+func TestCacheKeyedByBaseURLAndVersion(t *testing.T) {
+	cache := NewLRUCache(10)
+	calls := 0
+	srv, c1 := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		RespondGeocode()(w, r)
+	}), WithCache(cache, 0))
+	defer srv.Close()
+	srv2, c2 := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		RespondGeocode()(w, r)
+	}), WithCache(cache, 0))
+	defer srv2.Close()
+	c3 := NewClient("test_key", WithBaseURL(srv.URL), WithAPIVersion("v2"), WithCache(cache, 0))
+
+	for _, c := range []*Client{c1, c2, c3, c1} {
+		if _, err := c.Geocode("1600 Pennsylvania Ave"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("%d requests, want 3: one per base URL and version, then a hit", calls)
+	}
+}
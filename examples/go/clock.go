@@ -0,0 +1,38 @@
+// CSV2GEO API - Go Geocoding Example: time source
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// clock is the client's source of time. Everything that reads the time or
+// waits (backoff, rate limiting, the circuit breaker, job polling, timing,
+// logging and LRUCache TTLs) goes through it, so tests can substitute a
+// fake clock that advances instantly.
+type clock interface {
+	Now() time.Time
+	// Sleep waits for d, returning early with ctx.Err() if ctx is done
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock is the wall clock
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleepContext(ctx, d)
+}
+
+// withClock replaces the wall clock, for tests
+func withClock(clk clock) Option {
+	return func(c *Client) {
+		c.clock = clk
+	}
+}
+
+// since is time.Since on the client's clock
+func (c *Client) since(t time.Time) time.Duration {
+	return c.clock.Now().Sub(t)
+}
@@ -0,0 +1,27 @@
+// CSV2GEO API - Go Geocoding Example: releasing client resources
+package main
+
+import "errors"
+
+// ErrClientClosed is returned for calls made on a Client after Close
+var ErrClientClosed = errors.New("csv2geo: client closed")
+
+// Close releases the client's resources: idle keep-alive connections of
+// its HTTP client are closed. The client starts no goroutines of its own
+// (its rate limiter and circuit breaker work lazily), so nothing else
+// needs stopping. The client must not be used after Close; requests fail
+// with ErrClientClosed. Close is idempotent and safe to call from several
+// goroutines; only the first call does anything. It always returns nil.
+//
+// A Cache given to WithCache belongs to the caller, who may share it
+// between clients, so Close leaves it alone; close it yourself once no
+// client uses it. Note that the default HTTP client is http.DefaultClient,
+// shared with the rest of the program, whose idle connections are closed
+// too.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		c.closed.Store(true)
+		c.HTTPClient.CloseIdleConnections()
+	})
+	return nil
+}
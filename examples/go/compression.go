@@ -0,0 +1,68 @@
+// CSV2GEO API - Go Geocoding Example: gzip compression
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipMinBody is the smallest POST body worth compressing
+const gzipMinBody = 1024
+
+// WithCompression asks for gzip-compressed responses and decodes them
+// transparently, and gzips POST bodies of 1 KiB or more (such as large
+// batches) with Content-Encoding: gzip. Uncompressed responses are still
+// accepted, even if mislabelled as gzip.
+func WithCompression() Option {
+	return func(c *Client) {
+		c.compression = true
+	}
+}
+
+// gzipBytes compresses b
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeGzip replaces a gzip-encoded resp.Body with a decompressing one.
+// The body is sniffed for the gzip magic number first, so a plain body is
+// passed through untouched whatever the headers say.
+func decodeGzip(resp *http.Response) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+	br := bufio.NewReader(resp.Body)
+	body := &gzipBody{Reader: br, closer: resp.Body}
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		if zr, err := gzip.NewReader(br); err == nil {
+			body.Reader = zr
+		}
+	}
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+}
+
+// gzipBody reads the (possibly) decompressed stream and closes the
+// original body
+type gzipBody struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (b *gzipBody) Close() error {
+	return b.closer.Close()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var compressionFixture = GeocodeResponse{
+	Query: "1600 Pennsylvania Ave",
+	Results: []GeocodeResult{{
+		FormattedAddress: "1600 Pennsylvania Ave NW, Washington, DC 20500",
+		Location:         Location{Lat: 38.8977, Lng: -77.0365},
+		Accuracy:         AccuracyRooftop,
+		Confidence:       0.98,
+	}},
+}
+
+func TestCompressionDecodesGzipResponse(t *testing.T) {
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Accept-Encoding = %q, want gzip", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		json.NewEncoder(zw).Encode(compressionFixture)
+		zw.Close()
+	}), WithCompression())
+	defer srv.Close()
+
+	got, err := c.Geocode("1600 Pennsylvania Ave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.recordTiming(0, 0) // timings vary between runs
+	if !reflect.DeepEqual(*got, compressionFixture) {
+		t.Errorf("got %+v, want %+v", *got, compressionFixture)
+	}
+}
+
+func TestCompressionFallsBackToPlainResponse(t *testing.T) {
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip") // mislabelled
+		json.NewEncoder(w).Encode(compressionFixture)
+	}), WithCompression())
+	defer srv.Close()
+
+	got, err := c.Geocode("1600 Pennsylvania Ave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.recordTiming(0, 0) // timings vary between runs
+	if !reflect.DeepEqual(*got, compressionFixture) {
+		t.Errorf("got %+v, want %+v", *got, compressionFixture)
+	}
+}
+
+func TestCompressionGzipsLargeBatchBody(t *testing.T) {
+	addresses := make([]string, 100)
+	for i := range addresses {
+		addresses[i] = "1600 Pennsylvania Ave NW, Washington, DC"
+	}
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Content-Encoding = %q, want gzip", r.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(zr)
+		var req BatchGeocodeRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(req.Addresses, addresses) {
+			t.Error("decompressed batch body does not match input")
+		}
+		RespondJSON(http.StatusOK, BatchGeocodeResponse{})(w, r)
+	}), WithCompression())
+	defer srv.Close()
+
+	if _, err := c.BatchGeocode(addresses); err != nil {
+		t.Fatal(err)
+	}
+}
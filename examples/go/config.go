@@ -0,0 +1,146 @@
+// CSV2GEO API - Go Geocoding Example: configuration from a struct
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Config holds client settings in a form that can be loaded from a JSON
+// or YAML file, or filled in from the environment, instead of being
+// spelled out as options. Zero fields keep the client defaults. Durations
+// are written as strings such as "30s" or "1m30s".
+type Config struct {
+	APIKey     string   `json:"api_key" yaml:"api_key"`
+	BaseURL    string   `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+	APIVersion string   `json:"api_version,omitempty" yaml:"api_version,omitempty"`
+	AuthMode   AuthMode `json:"auth_mode,omitempty" yaml:"auth_mode,omitempty"`
+	UserAgent  string   `json:"user_agent,omitempty" yaml:"user_agent,omitempty"`
+	Proxy      string   `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+
+	// Timeout bounds each HTTP attempt, as http.Client.Timeout does
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// Retries and RetryDelay are passed to WithRetry, RetryBudget to
+	// WithRetryBudget
+	Retries     int      `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryDelay  Duration `json:"retry_delay,omitempty" yaml:"retry_delay,omitempty"`
+	RetryBudget Duration `json:"retry_budget,omitempty" yaml:"retry_budget,omitempty"`
+
+	// RateLimit is in requests per second, see WithRateLimit
+	RateLimit float64 `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	RateBurst int     `json:"rate_burst,omitempty" yaml:"rate_burst,omitempty"`
+
+	// CircuitThreshold and CircuitCooldown are passed to WithCircuitBreaker
+	CircuitThreshold int      `json:"circuit_threshold,omitempty" yaml:"circuit_threshold,omitempty"`
+	CircuitCooldown  Duration `json:"circuit_cooldown,omitempty" yaml:"circuit_cooldown,omitempty"`
+
+	BatchSize      int  `json:"batch_size,omitempty" yaml:"batch_size,omitempty"`
+	Compression    bool `json:"compression,omitempty" yaml:"compression,omitempty"`
+	StrictDecoding bool `json:"strict_decoding,omitempty" yaml:"strict_decoding,omitempty"`
+}
+
+// Duration is a time.Duration that reads and writes as a string such as
+// "30s", for config files
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// validate rejects settings the options would silently clamp or ignore
+func (cfg Config) validate() error {
+	switch {
+	case cfg.APIKey == "":
+		return errors.New("config: api_key is not set")
+	case cfg.Timeout < 0:
+		return fmt.Errorf("config: timeout %v: must not be negative", time.Duration(cfg.Timeout))
+	case cfg.Retries < 0:
+		return fmt.Errorf("config: retries %d: must not be negative", cfg.Retries)
+	case cfg.RetryDelay < 0:
+		return fmt.Errorf("config: retry_delay %v: must not be negative", time.Duration(cfg.RetryDelay))
+	case cfg.RetryBudget < 0:
+		return fmt.Errorf("config: retry_budget %v: must not be negative", time.Duration(cfg.RetryBudget))
+	case cfg.RateLimit < 0:
+		return fmt.Errorf("config: rate_limit %v: must not be negative", cfg.RateLimit)
+	case cfg.RateBurst < 0:
+		return fmt.Errorf("config: rate_burst %d: must not be negative", cfg.RateBurst)
+	case cfg.CircuitThreshold < 0:
+		return fmt.Errorf("config: circuit_threshold %d: must not be negative", cfg.CircuitThreshold)
+	case cfg.BatchSize < 0:
+		return fmt.Errorf("config: batch_size %d: must not be negative", cfg.BatchSize)
+	}
+	return nil
+}
+
+// options translates cfg into the equivalent Options
+func (cfg Config) options() []Option {
+	var opts []Option
+	if cfg.Timeout > 0 {
+		// Before WithProxy, which keeps the Timeout of the client it replaces
+		opts = append(opts, WithHTTPClient(&http.Client{Timeout: time.Duration(cfg.Timeout)}))
+	}
+	if cfg.BaseURL != "" {
+		opts = append(opts, WithBaseURL(cfg.BaseURL))
+	}
+	if cfg.APIVersion != "" {
+		opts = append(opts, WithAPIVersion(cfg.APIVersion))
+	}
+	if cfg.AuthMode != AuthBearer {
+		opts = append(opts, WithAuthMode(cfg.AuthMode))
+	}
+	if cfg.UserAgent != "" {
+		opts = append(opts, WithUserAgent(cfg.UserAgent))
+	}
+	if cfg.Proxy != "" {
+		opts = append(opts, WithProxy(cfg.Proxy))
+	}
+	if cfg.Retries > 0 {
+		opts = append(opts, WithRetry(cfg.Retries, time.Duration(cfg.RetryDelay)))
+	}
+	if cfg.RetryBudget > 0 {
+		opts = append(opts, WithRetryBudget(time.Duration(cfg.RetryBudget)))
+	}
+	if cfg.RateLimit > 0 {
+		opts = append(opts, WithRateLimit(cfg.RateLimit, cfg.RateBurst))
+	}
+	if cfg.CircuitThreshold > 0 {
+		opts = append(opts, WithCircuitBreaker(cfg.CircuitThreshold, time.Duration(cfg.CircuitCooldown)))
+	}
+	if cfg.BatchSize > 0 {
+		opts = append(opts, WithBatchSize(cfg.BatchSize))
+	}
+	if cfg.Compression {
+		opts = append(opts, WithCompression())
+	}
+	if cfg.StrictDecoding {
+		opts = append(opts, WithStrictDecoding())
+	}
+	return opts
+}
+
+// NewClientFromConfig validates cfg and creates a client from it. opts are
+// applied after the settings from cfg, for what a config file can't hold,
+// such as a Logger or a Cache. Option errors that NewClient would leave to
+// Err, such as a malformed Proxy, are returned here instead.
+func NewClientFromConfig(cfg Config, opts ...Option) (*Client, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	c := NewClient(cfg.APIKey, append(cfg.options(), opts...)...)
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
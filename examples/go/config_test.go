@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientFromConfigJSON(t *testing.T) {
+	var cfg Config
+	err := json.Unmarshal([]byte(`{
+		"api_key": "test_key",
+		"base_url": "https://staging.csv2geo.com/api",
+		"auth_mode": "header",
+		"timeout": "15s",
+		"retries": 3,
+		"retry_delay": "250ms",
+		"rate_limit": 5,
+		"batch_size": 50
+	}`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BaseURL != "https://staging.csv2geo.com/api" || c.authMode != AuthHeader {
+		t.Errorf("BaseURL = %q, authMode = %v", c.BaseURL, c.authMode)
+	}
+	if c.HTTPClient.Timeout != 15*time.Second {
+		t.Errorf("Timeout = %v, want 15s", c.HTTPClient.Timeout)
+	}
+	if c.maxRetries != 3 || c.retryBaseDelay != 250*time.Millisecond {
+		t.Errorf("retries = %d, %v; want 3, 250ms", c.maxRetries, c.retryBaseDelay)
+	}
+	if c.limiter == nil || c.batchSize != 50 {
+		t.Errorf("limiter = %v, batchSize = %d", c.limiter, c.batchSize)
+	}
+}
+
+func TestNewClientFromConfigInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{}, "api_key"},
+		{Config{APIKey: "k", Retries: -1}, "retries"},
+		{Config{APIKey: "k", Proxy: "::bad"}, "proxy"},
+	} {
+		if _, err := NewClientFromConfig(tc.cfg); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("NewClientFromConfig(%+v) = %v, want error mentioning %q", tc.cfg, err, tc.want)
+		}
+	}
+	var m AuthMode
+	if err := json.Unmarshal([]byte(`"cookie"`), &m); err == nil {
+		t.Error("unknown auth mode unmarshalled without error")
+	}
+}
@@ -0,0 +1,537 @@
+// CSV2GEO API - Go Geocoding Example: streaming CSV geocoding
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ColumnMapping names the CSV columns that make up an address. Empty fields
+// are not used. The values of the mapped columns are joined with ", " in
+// field order to build each row's query, so a file with a single address
+// column only needs Address set.
+//
+// Fallback lists further columns, each holding a whole address, to try in
+// order when a row's query fails, matches nothing or only gives a best
+// result with a Confidence below MinConfidence, e.g. []string{"address_alt"}.
+// The first query whose best result meets MinConfidence is used; if none
+// does, the most confident result found is. Setting Fallback adds a
+// matched_column output column naming the column that produced the result,
+// with the mapped columns joined by "+" for the primary query.
+type ColumnMapping struct {
+	Address  string
+	Street   string
+	City     string
+	State    string
+	Postcode string // e.g. "zip"
+	Country  string
+
+	Fallback      []string
+	MinConfidence float64
+}
+
+// columns returns the mapped column names in query order
+func (m ColumnMapping) columns() []string {
+	var cols []string
+	for _, name := range []string{m.Address, m.Street, m.City, m.State, m.Postcode, m.Country} {
+		if name != "" {
+			cols = append(cols, name)
+		}
+	}
+	return cols
+}
+
+// resolve finds the header index of every mapped column, naming the first
+// one that is missing
+func (m ColumnMapping) resolve(header []string) ([]int, error) {
+	cols := m.columns()
+	if len(cols) == 0 {
+		return nil, errors.New("column mapping names no columns")
+	}
+	return headerIndices(header, cols, "mapped")
+}
+
+// resolveFallback finds the header index of every Fallback column
+func (m ColumnMapping) resolveFallback(header []string) ([]int, error) {
+	return headerIndices(header, m.Fallback, "fallback")
+}
+
+// headerIndices finds the index of each of cols in header, using the first
+// of duplicate names. kind describes the columns in the error for a
+// missing one.
+func headerIndices(header, cols []string, kind string) ([]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, dup := index[name]; !dup {
+			index[name] = i
+		}
+	}
+	idx := make([]int, len(cols))
+	for i, name := range cols {
+		j, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("%s column %q not found in CSV header", kind, name)
+		}
+		idx[i] = j
+	}
+	return idx, nil
+}
+
+// assembleQuery joins the non-empty mapped cells of record, skipping
+// columns a short record does not have
+func assembleQuery(record []string, idx []int) string {
+	parts := make([]string, 0, len(idx))
+	for _, i := range idx {
+		if i >= len(record) {
+			continue
+		}
+		if v := strings.TrimSpace(record[i]); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GeocodeCSV geocodes a CSV stream using the default client
+func GeocodeCSV(ctx context.Context, r io.Reader, addressColumn string, w io.Writer, opts ...CallOption) error {
+	return defaultClient.GeocodeCSV(ctx, r, addressColumn, w, opts...)
+}
+
+// GeocodeCSV reads a CSV with a header row from r, geocodes addressColumn
+// row by row and writes the rows to w with lat, lng, accuracy and error
+// columns appended, coordinates rounded to 6 decimals. Rows are streamed,
+// not buffered, and the original columns are kept in order; fields
+// containing delimiters, quotes or newlines are re-quoted on output. A row
+// that fails to geocode gets empty coordinate cells and the failure in its
+// error cell; it does not stop the job. So does a row with more or fewer
+// fields than the header, which is not geocoded; it is padded or trimmed
+// to the header's width, with any extra fields quoted in its error cell.
+// Only other CSV read/write errors and context cancellation abort, with a
+// *PartialError once rows have been written. Each row is flushed to w as
+// soon as it is geocoded.
+//
+// opts are passed to every geocode request. WithProgress is called after
+// each row with a total of 0, since the length of the stream is unknown.
+// WithStartRow skips rows already processed by an earlier run.
+func (c *Client) GeocodeCSV(ctx context.Context, r io.Reader, addressColumn string, w io.Writer, opts ...CallOption) error {
+	return c.GeocodeCSVMapping(ctx, r, ColumnMapping{Address: addressColumn}, w, opts...)
+}
+
+// GeocodeCSVMapping geocodes a CSV stream using the default client
+func GeocodeCSVMapping(ctx context.Context, r io.Reader, mapping ColumnMapping, w io.Writer, opts ...CallOption) error {
+	return defaultClient.GeocodeCSVMapping(ctx, r, mapping, w, opts...)
+}
+
+// GeocodeCSVMapping is GeocodeCSV for addresses split across several
+// columns. Every mapped column must be present in the header; otherwise
+// an error naming the missing column is returned before any row is read.
+func (c *Client) GeocodeCSVMapping(ctx context.Context, r io.Reader, mapping ColumnMapping, w io.Writer, opts ...CallOption) error {
+	_, err := c.geocodeCSV(ctx, r, mapping, w, opts)
+	return err
+}
+
+// Stats counts the rows processed by a CSV geocoding run
+type Stats struct {
+	Total     int
+	Succeeded int
+	Failed    int
+}
+
+// geocodeCSV implements GeocodeCSVMapping, counting rows as it goes
+func (c *Client) geocodeCSV(ctx context.Context, r io.Reader, mapping ColumnMapping, w io.Writer, opts []CallOption) (Stats, error) {
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return Stats{}, err
+	}
+	cw := csv.NewWriter(w)
+	if cfg.delimiter != 0 {
+		cw.Comma = cfg.delimiter
+	}
+
+	extra := []string{"lat", "lng", "accuracy", "error"}
+	if len(mapping.Fallback) > 0 {
+		extra = append(extra, "matched_column")
+	}
+	writeHeader := func(header []string) error {
+		if cfg.startRow > 0 {
+			return nil // resuming: the header is already in the earlier output
+		}
+		if err := cw.Write(append(header, extra...)); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		return nil
+	}
+	writeRow := func(row csvRow) error {
+		cells := append(row.record, resultCells(row.best, row.err)...)
+		if len(mapping.Fallback) > 0 {
+			cells = append(cells, row.matched)
+		}
+		if err := cw.Write(cells); err != nil {
+			return fmt.Errorf("failed to write CSV row %d: %w", row.line, err)
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV row %d: %w", row.line, err)
+		}
+		return nil
+	}
+	stats, err := c.geocodeRows(ctx, r, mapping, cfg, opts, writeHeader, writeRow)
+	if err != nil {
+		return stats, err
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return stats, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return stats, nil
+}
+
+// resultCells returns the lat, lng, accuracy and error cells for a row
+// whose best result is best, or which failed with err
+func resultCells(best *GeocodeResult, err error) []string {
+	if err != nil {
+		return []string{"", "", "", err.Error()}
+	}
+	return []string{
+		formatCoord(roundCoord(best.Location.Lat, defaultCoordinatePrecision)),
+		formatCoord(roundCoord(best.Location.Lng, defaultCoordinatePrecision)),
+		string(best.Accuracy),
+		"",
+	}
+}
+
+// PartialError is returned when CSV geocoding stops partway, after the
+// header, because of cancellation or a read or write error. Rows counts
+// the data rows fully written before the failure; output is flushed after
+// every row, so they are all in the output. To resume, run again on the
+// same input with WithStartRow set to the earlier start row plus Rows and
+// append the output to the earlier one.
+type PartialError struct {
+	Rows int
+	Err  error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("CSV geocoding stopped after %d row(s): %v", e.Rows, e.Err)
+}
+
+func (e *PartialError) Unwrap() error { return e.Err }
+
+// csvRow is one geocoded data row of a CSV input
+type csvRow struct {
+	line    int // 1-based line number, the header being line 1
+	record  []string
+	query   string         // the query that produced best
+	matched string         // the column(s) query came from
+	best    *GeocodeResult // nil if err is set
+	err     error          // why the row failed to geocode
+}
+
+// geocodeRows reads a CSV with a header row from r, passes the header to
+// header and geocodes each data row in turn, passing the result to row.
+// Failed geocodes, and rows whose field count differs from the header's,
+// are reported per row; only other CSV read errors, errors from the
+// callbacks and context cancellation abort.
+func (c *Client) geocodeRows(ctx context.Context, r io.Reader, mapping ColumnMapping, cfg *callConfig, opts []CallOption,
+	header func([]string) error, row func(csvRow) error) (Stats, error) {
+	var stats Stats
+	progress := newProgress(cfg.progress, 0)
+
+	cr := csv.NewReader(r)
+	if cfg.delimiter != 0 {
+		cr.Comma = cfg.delimiter
+	}
+	cr.LazyQuotes = cfg.lazyQuotes
+	cr.FieldsPerRecord = -1 // ragged rows fail on their own, see below
+
+	names, err := cr.Read()
+	if err == io.EOF {
+		return stats, errNoCSVHeader
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	idx, err := mapping.resolve(names)
+	if err != nil {
+		return stats, err
+	}
+	fallback, err := mapping.resolveFallback(names)
+	if err != nil {
+		return stats, err
+	}
+	primary := strings.Join(mapping.columns(), "+")
+	if err := header(names); err != nil {
+		return stats, err
+	}
+
+	// Past the header, a failure leaves stats.Total rows written
+	partial := func(err error) (Stats, error) {
+		return stats, &PartialError{Rows: stats.Total, Err: err}
+	}
+	for line := 2; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return partial(err)
+		}
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return partial(fmt.Errorf("failed to read CSV row %d: %w", line, err))
+		}
+		if line-2 < cfg.startRow {
+			continue
+		}
+
+		out := csvRow{line: line, record: record, query: assembleQuery(record, idx), matched: primary}
+		if len(record) != len(names) {
+			// Not geocoded: with cells missing or extra, the mapped
+			// columns can't be trusted. The row is padded or trimmed to
+			// the header's width so the appended columns still line up;
+			// trimmed cells are kept in the error message.
+			out.err = fmt.Errorf("row has %d fields, header has %d", len(record), len(names))
+			if len(record) > len(names) {
+				out.err = fmt.Errorf("%w; extra fields %q", out.err, record[len(names):])
+				out.record = record[:len(names):len(names)]
+			}
+			for len(out.record) < len(names) {
+				out.record = append(out.record, "")
+			}
+		} else if err := c.geocodeRow(ctx, &out, mapping, fallback, opts); err != nil {
+			return partial(err)
+		}
+
+		if err := row(out); err != nil {
+			return partial(err)
+		}
+		stats.Total++
+		if out.err == nil {
+			stats.Succeeded++
+		} else {
+			stats.Failed++
+		}
+		progress.step()
+	}
+	return stats, nil
+}
+
+// geocodeRow geocodes row.query, then the row's fallback columns as long as
+// no result meets mapping.MinConfidence, and fills in the result. It only
+// returns an error if ctx is done.
+func (c *Client) geocodeRow(ctx context.Context, row *csvRow, mapping ColumnMapping, fallback []int, opts []CallOption) error {
+	try := func(query string) (*GeocodeResult, error) {
+		resp, err := c.GeocodeContext(ctx, query, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return resp.BestResult()
+	}
+
+	row.best, row.err = try(row.query)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	for i, col := range fallback {
+		if row.err == nil && row.best.MeetsConfidence(mapping.MinConfidence) {
+			break
+		}
+		if col >= len(row.record) {
+			continue
+		}
+		query := strings.TrimSpace(row.record[col])
+		if query == "" {
+			continue
+		}
+		best, err := try(query)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil && (row.err != nil || best.Confidence > row.best.Confidence) {
+			row.query, row.matched, row.best, row.err = query, mapping.Fallback[i], best, nil
+		}
+	}
+	return nil
+}
+
+// errNoCSVHeader is returned for empty CSV input
+var errNoCSVHeader = errors.New("CSV input has no header row")
+
+// GeocodeFile geocodes a CSV file using the default client
+func GeocodeFile(ctx context.Context, inPath, outPath string, mapping ColumnMapping, opts ...CallOption) (Stats, error) {
+	return defaultClient.GeocodeFile(ctx, inPath, outPath, mapping, opts...)
+}
+
+// GeocodeFile geocodes the CSV at inPath into outPath, as GeocodeCSVMapping
+// does for streams, and returns row counts. Output goes to a temporary file
+// in the same directory that is renamed over outPath only on success, so a
+// failed run never leaves a half-written outPath behind.
+func (c *Client) GeocodeFile(ctx context.Context, inPath, outPath string, mapping ColumnMapping, opts ...CallOption) (Stats, error) {
+	in, err := os.Open(inPath)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer in.Close()
+
+	tmp, err := createOutputTemp(outPath)
+	if err != nil {
+		return Stats{}, err
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	stats, err := c.geocodeCSV(ctx, in, mapping, tmp, opts)
+	if err != nil {
+		var partial *PartialError
+		if errors.As(err, &partial) {
+			err = partial.Err // the partial output is discarded below
+		}
+		return stats, err
+	}
+	if err := tmp.Close(); err != nil {
+		return stats, err
+	}
+	if err := os.Rename(tmpPath, outPath); err != nil {
+		return stats, err
+	}
+	committed = true
+	return stats, nil
+}
+
+// createOutputTemp creates the temporary file GeocodeFile renames over
+// outPath, with the permissions outPath will end up with: those of an
+// existing outPath, or else 0666 less the umask, as os.Create gives. It
+// does not use os.CreateTemp, whose files are always 0600.
+func createOutputTemp(outPath string) (*os.File, error) {
+	perm := os.FileMode(0o666)
+	existing, statErr := os.Stat(outPath)
+	if statErr == nil {
+		perm = existing.Mode().Perm()
+	}
+	prefix := filepath.Join(filepath.Dir(outPath), "."+filepath.Base(outPath)+".")
+	for try := 0; ; try++ {
+		name := prefix + strconv.FormatUint(rand.Uint64(), 36) + ".tmp"
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) && try < 100 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if statErr == nil {
+			// OpenFile applied the umask; keep the existing mode exactly
+			if err := f.Chmod(perm); err != nil {
+				f.Close()
+				os.Remove(name)
+				return nil, err
+			}
+		}
+		return f, nil
+	}
+}
+
+// GeocodeCSVHeader returns the column names for GeocodeResult.CSVRecord:
+//
+//	formatted_address, lat, lng, accuracy, confidence,
+//	house_number, street, city, state, postcode, country
+//
+// The order is stable; new columns will only ever be appended.
+func GeocodeCSVHeader() []string {
+	return []string{
+		"formatted_address", "lat", "lng", "accuracy", "confidence",
+		"house_number", "street", "city", "state", "postcode", "country",
+	}
+}
+
+// CSVRecord flattens r into a row matching GeocodeCSVHeader, for writing
+// with encoding/csv. Coordinates are rounded to 6 decimals unless
+// WithCoordinatePrecision says otherwise; they and the confidence are
+// written without trailing zeros.
+func (r GeocodeResult) CSVRecord(opts ...FormatOption) []string {
+	cfg := newFormatConfig(opts)
+	c := r.Components
+	return []string{
+		r.FormattedAddress,
+		formatCoord(cfg.round(r.Location.Lat)),
+		formatCoord(cfg.round(r.Location.Lng)),
+		string(r.Accuracy),
+		strconv.FormatFloat(r.Confidence, 'f', -1, 64),
+		c.HouseNumber, c.Street, c.City, c.State, c.Postcode, c.Country,
+	}
+}
+
+// WriteBatchCSV joins the results of BatchGeocodeConcurrent back onto the
+// CSV they were geocoded from. original is the whole file as read by
+// csv.Reader.ReadAll, header row first, and results has one item per data
+// row, matched to original[Index+1]. Each row is written to w with the
+// same lat, lng, accuracy and error columns GeocodeCSV appends, from the
+// best result of its item.
+//
+// An error is returned, before anything is written, if original has no
+// header row, if any row has a different number of fields than the header
+// (which would push the appended columns out from under their names), if
+// the number of results differs from the number of data rows, or if an
+// item's Index is out of range or repeated. Rows are numbered as CSV
+// lines, the header being row 1.
+func WriteBatchCSV(w io.Writer, original [][]string, results []BatchResultItem) error {
+	if len(original) == 0 {
+		return errNoCSVHeader
+	}
+	for i, record := range original[1:] {
+		if len(record) != len(original[0]) {
+			return fmt.Errorf("row %d has %d fields, header has %d", i+2, len(record), len(original[0]))
+		}
+	}
+	rows := original[1:]
+	if len(results) != len(rows) {
+		return fmt.Errorf("cannot merge %d results into %d data rows", len(results), len(rows))
+	}
+	byRow := make([]*BatchResultItem, len(rows))
+	for i := range results {
+		item := &results[i]
+		if item.Index < 0 || item.Index >= len(rows) {
+			return fmt.Errorf("result %d has index %d, outside the %d data rows", i, item.Index, len(rows))
+		}
+		if byRow[item.Index] != nil {
+			return fmt.Errorf("result %d repeats index %d", i, item.Index)
+		}
+		byRow[item.Index] = item
+	}
+
+	cw := csv.NewWriter(w)
+	header := append(append([]string(nil), original[0]...), "lat", "lng", "accuracy", "error")
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for i, record := range rows {
+		item := byRow[i]
+		err := item.Err
+		var best *GeocodeResult
+		if err == nil {
+			best, err = item.Response.BestResult()
+		}
+		cells := append(append([]string(nil), record...), resultCells(best, err)...)
+		if err := cw.Write(cells); err != nil {
+			return fmt.Errorf("failed to write CSV row %d: %w", i+2, err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
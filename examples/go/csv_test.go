@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// echoServer answers every geocode with one rooftop result and records
+// the queries it received
+func echoServer(t *testing.T) (*Client, func() []string) {
+	var mu sync.Mutex
+	var queries []string
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query().Get("q"))
+		mu.Unlock()
+		RespondGeocode(GeocodeResult{
+			FormattedAddress: r.URL.Query().Get("q"),
+			Location:         Location{Lat: 40.5, Lng: -74.25},
+			Accuracy:         AccuracyRooftop,
+		})(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return c, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), queries...)
+	}
+}
+
+func TestGeocodeCSVQuotedFields(t *testing.T) {
+	c, queries := echoServer(t)
+	input := "id,address,note\n" +
+		"1,\"123 Main St, Apt 4\",plain\n" +
+		"2,\"The \"\"Old\"\" Mill, Rd 9\",\"line one\nline two\"\n"
+
+	var out strings.Builder
+	if err := c.GeocodeCSV(context.Background(), strings.NewReader(input), "address", &out); err != nil {
+		t.Fatal(err)
+	}
+
+	wantQueries := []string{"123 Main St, Apt 4", `The "Old" Mill, Rd 9`}
+	if got := queries(); !reflect.DeepEqual(got, wantQueries) {
+		t.Errorf("queries = %q, want %q", got, wantQueries)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v\n%s", err, out.String())
+	}
+	want := [][]string{
+		{"id", "address", "note", "lat", "lng", "accuracy", "error"},
+		{"1", "123 Main St, Apt 4", "plain", "40.5", "-74.25", "rooftop", ""},
+		{"2", `The "Old" Mill, Rd 9`, "line one\nline two", "40.5", "-74.25", "rooftop", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+	if !strings.Contains(out.String(), `"The ""Old"" Mill, Rd 9"`) {
+		t.Errorf("output did not re-quote embedded quotes:\n%s", out.String())
+	}
+}
+
+func TestGeocodeCSVLazyQuotes(t *testing.T) {
+	c, _ := echoServer(t)
+	input := "address\n12\" Main St\n"
+
+	var out strings.Builder
+	if err := c.GeocodeCSV(context.Background(), strings.NewReader(input), "address", &out); err == nil {
+		t.Fatal("want parse error for bare quote without WithLazyQuotes")
+	}
+
+	out.Reset()
+	if err := c.GeocodeCSV(context.Background(), strings.NewReader(input), "address", &out, WithLazyQuotes()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "address,lat,lng,accuracy,error\n\"12\"\" Main St\",") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestGeocodeCSVDelimiter(t *testing.T) {
+	c, queries := echoServer(t)
+	input := "street\tcity\n1 Main St, Unit 2\tSpringfield\n"
+
+	var out strings.Builder
+	mapping := ColumnMapping{Street: "street", City: "city"}
+	if err := c.GeocodeCSVMapping(context.Background(), strings.NewReader(input), mapping, &out, WithDelimiter('\t')); err != nil {
+		t.Fatal(err)
+	}
+	if got := queries(); len(got) != 1 || got[0] != "1 Main St, Unit 2, Springfield" {
+		t.Errorf("queries = %q", got)
+	}
+	if want := "street\tcity\tlat\tlng\taccuracy\terror\n"; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("output header = %q, want prefix %q", out.String(), want)
+	}
+}
+
+func TestGeocodeCSVMissingColumn(t *testing.T) {
+	c, queries := echoServer(t)
+	input := "street,city\n1 Main St,Springfield\n"
+
+	var out strings.Builder
+	err := c.GeocodeCSVMapping(context.Background(), strings.NewReader(input), ColumnMapping{Street: "street", Postcode: "zip"}, &out)
+	if err == nil || !strings.Contains(err.Error(), `"zip"`) {
+		t.Fatalf("err = %v, want one naming the zip column", err)
+	}
+	if len(queries()) != 0 {
+		t.Error("rows were geocoded despite the missing column")
+	}
+}
+
+func TestGeocodeCSVFallbackColumn(t *testing.T) {
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		confidence := 0.3
+		if strings.HasPrefix(q, "good") {
+			confidence = 0.9
+		}
+		RespondGeocode(GeocodeResult{FormattedAddress: q, Accuracy: AccuracyRooftop, Confidence: confidence})(w, r)
+	}))
+	defer srv.Close()
+
+	input := "address,address_alt\n" +
+		"good primary,good alt\n" +
+		"vague primary,good alt\n" +
+		"vague primary,vague alt\n"
+	mapping := ColumnMapping{Address: "address", Fallback: []string{"address_alt"}, MinConfidence: 0.8}
+	var out strings.Builder
+	if err := c.GeocodeCSVMapping(context.Background(), strings.NewReader(input), mapping, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rows[0][len(rows[0])-1]; got != "matched_column" {
+		t.Fatalf("last header column = %q, want matched_column", got)
+	}
+	for i, want := range []string{"address", "address_alt", "address"} {
+		if got := rows[i+1][len(rows[i+1])-1]; got != want {
+			t.Errorf("row %d matched_column = %q, want %q", i+1, got, want)
+		}
+	}
+}
+
+func TestValidateCSV(t *testing.T) {
+	input := "id,address\n" +
+		"1,123 Main St\n" +
+		"2,  \n" +
+		"3,123  main st\n" +
+		"4,9 Elm St,extra\n" +
+		"5,9 Elm St\n"
+
+	report, err := ValidateCSV(strings.NewReader(input), ColumnMapping{Address: "address"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ValidationReport{
+		Rows:       5,
+		Empty:      1,
+		Duplicates: 1,
+		Malformed:  1,
+		Samples: []RowProblem{
+			{Line: 3, Problem: "empty address"},
+			{Line: 4, Problem: "duplicate of line 2", Query: "123  main st"},
+			{Line: 5, Problem: "wrong number of fields"},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %+v\nwant %+v", report, want)
+	}
+	if report.OK() {
+		t.Error("OK() = true for a report with problems")
+	}
+}
+
+func TestValidateCSVMissingColumn(t *testing.T) {
+	_, err := ValidateCSV(strings.NewReader("street,city\n1 Main St,Springfield\n"), ColumnMapping{Address: "address"})
+	if err == nil || !strings.Contains(err.Error(), `"address"`) {
+		t.Errorf("err = %v, want missing column error naming address", err)
+	}
+}
+
+func TestWriteBatchCSV(t *testing.T) {
+	c, _ := echoServer(t)
+	original := [][]string{
+		{"id", "address"},
+		{"1", "123 Main St"},
+		{"2", "  "},
+		{"3", "9 Elm St"},
+	}
+	addresses := []string{original[1][1], original[2][1], original[3][1]}
+	items, err := c.BatchGeocodeConcurrent(context.Background(), addresses, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := WriteBatchCSV(&out, original, items); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v\n%s", err, out.String())
+	}
+	want := [][]string{
+		{"id", "address", "lat", "lng", "accuracy", "error"},
+		{"1", "123 Main St", "40.5", "-74.25", "rooftop", ""},
+		{"2", "  ", "", "", "", ErrEmptyQuery.Error()},
+		{"3", "9 Elm St", "40.5", "-74.25", "rooftop", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+
+	if err := WriteBatchCSV(&out, original, items[:2]); err == nil || !strings.Contains(err.Error(), "2 results into 3 data rows") {
+		t.Errorf("short results: err = %v", err)
+	}
+	items[2].Index = 0
+	if err := WriteBatchCSV(&out, original, items); err == nil || !strings.Contains(err.Error(), "repeats index 0") {
+		t.Errorf("repeated index: err = %v", err)
+	}
+
+	ragged := [][]string{original[0], original[1], {"2"}, original[3]}
+	out.Reset()
+	if err := WriteBatchCSV(&out, ragged, items); err == nil || err.Error() != "row 3 has 1 fields, header has 2" {
+		t.Errorf("short row: err = %v", err)
+	}
+	ragged[2] = []string{"2", "  ", "extra"}
+	if err := WriteBatchCSV(&out, ragged, items); err == nil || err.Error() != "row 3 has 3 fields, header has 2" {
+		t.Errorf("long row: err = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("wrote %q before failing", out.String())
+	}
+}
+
+func TestGeocodeCSVRaggedRow(t *testing.T) {
+	c, queries := echoServer(t)
+	input := "id,address\n1,foo\n2\n3,bar\n4,baz,x,y\n"
+
+	var out strings.Builder
+	if err := c.GeocodeCSV(context.Background(), strings.NewReader(input), "address", &out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := queries(), []string{"foo", "bar"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("queries = %q, want %q", got, want)
+	}
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v\n%s", err, out.String())
+	}
+	want := [][]string{
+		{"id", "address", "lat", "lng", "accuracy", "error"},
+		{"1", "foo", "40.5", "-74.25", "rooftop", ""},
+		{"2", "", "", "", "", "row has 1 fields, header has 2"},
+		{"3", "bar", "40.5", "-74.25", "rooftop", ""},
+		{"4", "baz", "", "", "", `row has 4 fields, header has 2; extra fields ["x" "y"]`},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+	for i, row := range rows {
+		if len(row) != len(rows[0]) {
+			t.Errorf("output row %d has %d cells, header has %d", i+1, len(row), len(rows[0]))
+		}
+	}
+}
+
+func TestGeocodeFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permission bits")
+	}
+	c, _ := echoServer(t)
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.csv")
+	if err := os.WriteFile(in, []byte("address\n1 Main St\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := os.Create(filepath.Join(dir, "ref"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref.Close()
+	refInfo, _ := os.Stat(ref.Name())
+
+	out := filepath.Join(dir, "new.csv")
+	if _, err := c.GeocodeFile(context.Background(), in, out, ColumnMapping{Address: "address"}); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(out); info.Mode().Perm() != refInfo.Mode().Perm() {
+		t.Errorf("new output mode = %v, want %v as from os.Create", info.Mode().Perm(), refInfo.Mode().Perm())
+	}
+
+	existing := filepath.Join(dir, "existing.csv")
+	if err := os.WriteFile(existing, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(existing, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GeocodeFile(context.Background(), in, existing, ColumnMapping{Address: "address"}); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0o640 {
+		t.Errorf("replaced output mode = %v, want the existing 0640", info.Mode().Perm())
+	}
+}
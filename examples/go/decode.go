@@ -0,0 +1,39 @@
+// CSV2GEO API - Go Geocoding Example: response decoding
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// WithStrictDecoding makes a response fail to decode if it has a field the
+// client's types don't know, instead of silently dropping it. Use it in
+// tests and staging to notice new or renamed server fields early. Leave it
+// off in production: the API adds fields without notice, and a strict
+// client would start failing on a compatible change. The top-level "meta"
+// object, which the client deliberately ignores, is always allowed.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// decode unmarshals a response body into out, rejecting unknown fields
+// under WithStrictDecoding
+func (c *Client) decode(body []byte, out interface{}) error {
+	if !c.strictDecoding {
+		return json.Unmarshal(body, out)
+	}
+	var envelope map[string]json.RawMessage
+	if json.Unmarshal(body, &envelope) == nil {
+		if _, ok := envelope["meta"]; ok {
+			delete(envelope, "meta")
+			if stripped, err := json.Marshal(envelope); err == nil {
+				body = stripped
+			}
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	return dec.Decode(out)
+}
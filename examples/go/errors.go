@@ -0,0 +1,210 @@
+// CSV2GEO API - Go Geocoding Example: typed errors
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrNoResults is returned by convenience helpers such as GeocodeLatLng and
+// BestResult when a geocode matched nothing. The low-level methods
+// (Geocode, ReverseGeocode, ...) do not use it: they return a response
+// with an empty Results slice and a nil error.
+var ErrNoResults = errors.New("csv2geo: no results")
+
+// ErrEmptyQuery is returned, before any request is made, for an address
+// that is empty or only whitespace
+var ErrEmptyQuery = errors.New("csv2geo: empty query")
+
+// ErrInvalidCoordinate is returned, before any request is made, for a
+// latitude outside [-90, 90] or a longitude outside [-180, 180]
+var ErrInvalidCoordinate = errors.New("csv2geo: invalid coordinate")
+
+// validateCoordinate checks lat/lng ranges, rejecting NaN as well
+func validateCoordinate(lat, lng float64) error {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return fmt.Errorf("%w: latitude %v outside [-90, 90]", ErrInvalidCoordinate, lat)
+	}
+	if math.IsNaN(lng) || lng < -180 || lng > 180 {
+		return fmt.Errorf("%w: longitude %v outside [-180, 180]", ErrInvalidCoordinate, lng)
+	}
+	return nil
+}
+
+// APIError is returned for any non-200 response from the API. Use
+// errors.As to get at the status code:
+//
+//	var apiErr *APIError
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized { ... }
+//
+// IsAuthError, IsRateLimited, IsNotFound and IsServerError cover the
+// common cases.
+type APIError struct {
+	StatusCode int
+	Message    string
+	RawBody    []byte
+
+	op       string // e.g. "geocoding failed"
+	attempts int
+	stopped  string // why retries ended early, see WithRetryBudget
+}
+
+func (e *APIError) Error() string {
+	status := fmt.Sprintf("HTTP %d", e.StatusCode)
+	if e.attempts > 1 {
+		status = fmt.Sprintf("%s after %d attempts", status, e.attempts)
+	}
+	if e.stopped != "" {
+		status = fmt.Sprintf("%s, %s", status, e.stopped)
+	}
+	if e.op == "" {
+		return fmt.Sprintf("%s: %s", status, e.Message)
+	}
+	return fmt.Sprintf("%s (%s): %s", e.op, status, e.Message)
+}
+
+// apiStatus returns the status code of the *APIError in err's chain, or 0
+func apiStatus(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsAuthError reports whether err is an *APIError for a missing, invalid
+// or insufficiently privileged API key (401 or 403)
+func IsAuthError(err error) bool {
+	s := apiStatus(err)
+	return s == http.StatusUnauthorized || s == http.StatusForbidden
+}
+
+// IsRateLimited reports whether err is an *APIError for a 429 response
+func IsRateLimited(err error) bool {
+	return apiStatus(err) == http.StatusTooManyRequests
+}
+
+// IsNotFound reports whether err is an *APIError for a 404 response
+func IsNotFound(err error) bool {
+	return apiStatus(err) == http.StatusNotFound
+}
+
+// IsServerError reports whether err is an *APIError for a 5xx response
+func IsServerError(err error) bool {
+	s := apiStatus(err)
+	return s >= 500 && s <= 599
+}
+
+// apiErrorBody mirrors the Error schema in openapi.yaml
+type apiErrorBody struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// maxPlainMessage is the longest text/plain error body used verbatim as a
+// message
+const maxPlainMessage = 200
+
+// newAPIError builds an APIError, preferring the message from a JSON error
+// body and falling back to the raw body text. A body that is neither JSON
+// nor short plain text, such as a proxy's HTML error page, is summarized
+// as e.g. "non-JSON error (text/html, 512 bytes)" to keep the message
+// readable; RawBody still has all of it. The API key is redacted from both
+// the message and RawBody.
+func (c *Client) newAPIError(op string, status int, contentType string, body []byte, t tries) *APIError {
+	body = []byte(c.redact(string(body)))
+	msg := strings.TrimSpace(string(body))
+	var parsed apiErrorBody
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Error.Message != "" {
+		msg = parsed.Error.Message
+	} else if summary, ok := summarizeNonJSON(contentType, body); ok {
+		msg = summary
+	}
+	return &APIError{
+		StatusCode: status,
+		Message:    msg,
+		RawBody:    body,
+		op:         op,
+		attempts:   t.n,
+		stopped:    t.stopped,
+	}
+}
+
+// summarizeNonJSON returns a short description of an error body of the
+// given Content-Type, or false if the body is fine to show as is: JSON,
+// untyped, or a single short line of plain text
+func summarizeNonJSON(contentType string, body []byte) (string, bool) {
+	if contentType == "" {
+		return "", false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return "", false
+	case mediaType == "text/plain":
+		if text := strings.TrimSpace(string(body)); len(text) <= maxPlainMessage && !strings.Contains(text, "\n") {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("non-JSON error (%s, %d bytes)", mediaType, len(body)), true
+}
+
+// redact replaces every occurrence of the API key in s, raw or
+// query-escaped, with ***
+func (c *Client) redact(s string) string {
+	if c.APIKey == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, c.APIKey, "***")
+	if escaped := url.QueryEscape(c.APIKey); escaped != c.APIKey {
+		s = strings.ReplaceAll(s, escaped, "***")
+	}
+	return s
+}
+
+// redactedError is an error whose message had the API key removed. It
+// still unwraps to the original error.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactErr hides the API key in err's message, e.g. from a *url.Error
+// that carries the request URL
+func (c *Client) redactErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if redacted := c.redact(msg); redacted != msg {
+		return &redactedError{msg: redacted, err: err}
+	}
+	return err
+}
+
+// ChunkError reports a failed request for addresses[Start:End] of a
+// chunked BatchGeocode
+type ChunkError struct {
+	Start, End int
+	Err        error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("batch chunk [%d, %d) failed: %v", e.Start, e.End, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error { return e.Err }
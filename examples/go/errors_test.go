@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testKey = "geo_live_secret123"
+
+func TestAPIErrorRedactsKey(t *testing.T) {
+	srv, c := NewTestServer(RespondError(http.StatusUnauthorized, "invalid_api_key", "key "+testKey+" is invalid"))
+	defer srv.Close()
+	c.APIKey = testKey
+
+	_, err := c.Geocode("1600 Pennsylvania Ave")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", apiErr.StatusCode)
+	}
+	if strings.Contains(err.Error(), testKey) {
+		t.Errorf("error leaks API key: %q", err.Error())
+	}
+	if !strings.Contains(apiErr.Message, "key *** is invalid") {
+		t.Errorf("message = %q, want redacted key", apiErr.Message)
+	}
+	if strings.Contains(string(apiErr.RawBody), testKey) {
+		t.Errorf("raw body leaks API key: %q", apiErr.RawBody)
+	}
+}
+
+func TestRequestErrorRedactsKey(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close() // connection refused, so the *url.Error carries the full URL with the key
+
+	c := NewClient(testKey, WithBaseURL(srv.URL), WithAuthMode(AuthQuery))
+	_, err := c.Geocode("1600 Pennsylvania Ave")
+	if err == nil {
+		t.Fatal("want error from closed server")
+	}
+	if strings.Contains(err.Error(), testKey) {
+		t.Errorf("error leaks API key: %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), "***") {
+		t.Errorf("error = %q, want redaction marker", err.Error())
+	}
+}
+
+func TestAPIErrorSummarizesHTMLBody(t *testing.T) {
+	page := "<html>\n<head><title>502 Bad Gateway</title></head>\n<body><center><h1>502 Bad Gateway</h1></center></body>\n</html>\n"
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusBadGateway)
+		io.WriteString(w, page)
+	}))
+	defer srv.Close()
+
+	_, err := c.Geocode("1600 Pennsylvania Ave")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want *APIError, got %T: %v", err, err)
+	}
+	if want := fmt.Sprintf("non-JSON error (text/html, %d bytes)", len(page)); apiErr.Message != want {
+		t.Errorf("message = %q, want %q", apiErr.Message, want)
+	}
+	if string(apiErr.RawBody) != page {
+		t.Errorf("raw body = %q, want the full page", apiErr.RawBody)
+	}
+	if !IsServerError(err) {
+		t.Errorf("IsServerError(%v) = false, want true", err)
+	}
+}
+
+func TestEmptyQuerySendsNothing(t *testing.T) {
+	c, calls := countingServer(t, http.StatusOK)
+	ctx := context.Background()
+
+	if _, err := c.GeocodeContext(ctx, "  "); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("GeocodeContext: err = %v, want ErrEmptyQuery", err)
+	}
+	if _, err := c.GeocodeRaw(ctx, ""); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("GeocodeRaw: err = %v, want ErrEmptyQuery", err)
+	}
+	if _, err := c.GeocodeStructured(ctx, AddressComponents{City: " "}, WithCountry("US")); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("GeocodeStructured: err = %v, want ErrEmptyQuery", err)
+	}
+	_, err := c.SubmitBulkJob(ctx, []string{"1 Main St", "", " "})
+	if !errors.Is(err, ErrEmptyQuery) || !strings.Contains(err.Error(), "index 1, 2") {
+		t.Errorf("SubmitBulkJob: err = %v, want ErrEmptyQuery at index 1, 2", err)
+	}
+	if *calls != 0 {
+		t.Errorf("%d requests sent, want none", *calls)
+	}
+}
+
+func TestAutocompleteLimitCap(t *testing.T) {
+	c, calls := countingServer(t, http.StatusOK)
+
+	_, err := c.Autocomplete(context.Background(), "1600 Penn", WithLimit(20))
+	if err == nil || !strings.Contains(err.Error(), "at most 10") {
+		t.Errorf("err = %v, want a limit error naming the cap of 10", err)
+	}
+	if *calls != 0 {
+		t.Errorf("%d requests sent, want none", *calls)
+	}
+}
@@ -0,0 +1,204 @@
+// CSV2GEO API - Go Geocoding Example: distance helpers
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// MeanEarthRadius is the IUGG mean Earth radius in meters
+const MeanEarthRadius = 6371008.8
+
+// EarthRadius is the sphere radius, in meters, used by the distance
+// helpers. It defaults to MeanEarthRadius; set it to e.g. 6378137 to match
+// another tool's spherical model.
+var EarthRadius = MeanEarthRadius
+
+// metersPerMile is the international mile
+const metersPerMile = 1609.344
+
+// DistanceTo returns the great-circle distance in meters to other, using
+// the Haversine formula on a sphere of radius EarthRadius
+func (l Location) DistanceTo(other Location) float64 {
+	lat1 := l.Lat * math.Pi / 180
+	lat2 := other.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (other.Lng - l.Lng) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// DistanceMiles returns the great-circle distance in miles to other
+func (l Location) DistanceMiles(other Location) float64 {
+	return l.DistanceTo(other) / metersPerMile
+}
+
+// BoundingBox returns the south-west and north-east corners of the
+// smallest lat/lng box containing locs, treating longitude as linear from
+// -180 to 180. It returns zero Locations for an empty slice. For points on
+// both sides of the antimeridian use BoundingBoxAntimeridian.
+func BoundingBox(locs []Location) (sw, ne Location) {
+	if len(locs) == 0 {
+		return Location{}, Location{}
+	}
+	sw, ne = locs[0], locs[0]
+	for _, l := range locs[1:] {
+		sw.Lat = math.Min(sw.Lat, l.Lat)
+		sw.Lng = math.Min(sw.Lng, l.Lng)
+		ne.Lat = math.Max(ne.Lat, l.Lat)
+		ne.Lng = math.Max(ne.Lng, l.Lng)
+	}
+	return sw, ne
+}
+
+// BoundingBoxAntimeridian is BoundingBox allowing the box to cross the
+// antimeridian when that is narrower, e.g. for points in Fiji and Samoa.
+// A crossing box has sw.Lng > ne.Lng, the convention used by GeoJSON
+// bbox and most map libraries.
+func BoundingBoxAntimeridian(locs []Location) (sw, ne Location) {
+	sw, ne = BoundingBox(locs)
+	if len(locs) < 2 {
+		return sw, ne
+	}
+	lngs := make([]float64, len(locs))
+	for i, l := range locs {
+		lngs[i] = l.Lng
+	}
+	sort.Float64s(lngs)
+
+	// The box leaves out the widest gap between neighbouring longitudes.
+	// If that gap is the one across the antimeridian the plain box is
+	// already the narrowest.
+	widest := lngs[0] + 360 - lngs[len(lngs)-1]
+	west, east := lngs[0], lngs[len(lngs)-1]
+	for i := 1; i < len(lngs); i++ {
+		if gap := lngs[i] - lngs[i-1]; gap > widest {
+			widest = gap
+			west, east = lngs[i], lngs[i-1]
+		}
+	}
+	sw.Lng, ne.Lng = west, east
+	return sw, ne
+}
+
+// BoundingBox returns the box around the best result of every query, and
+// false if no query has a result
+func (r *BatchGeocodeResponse) BoundingBox() (Location, Location, bool) {
+	locs := r.bestLocations()
+	if len(locs) == 0 {
+		return Location{}, Location{}, false
+	}
+	sw, ne := BoundingBox(locs)
+	return sw, ne, true
+}
+
+// BoundingBoxAntimeridian is BoundingBox for results that may lie on both
+// sides of the antimeridian, using the free BoundingBoxAntimeridian: the
+// box may cross it, with sw.Lng > ne.Lng, when that is narrower
+func (r *BatchGeocodeResponse) BoundingBoxAntimeridian() (Location, Location, bool) {
+	locs := r.bestLocations()
+	if len(locs) == 0 {
+		return Location{}, Location{}, false
+	}
+	sw, ne := BoundingBoxAntimeridian(locs)
+	return sw, ne, true
+}
+
+// Coordinates returns the best result's location of every query as a
+// [lng, lat] pair, the order GeoJSON and most geometry packages use,
+// rounded as by WithCoordinatePrecision. By default queries without
+// results are skipped, so the pairs no longer line up with r.Results; with
+// WithNaNPlaceholders they get a [NaN, NaN] pair instead and pair i always
+// belongs to query i. Check for placeholders with math.IsNaN.
+func (r *BatchGeocodeResponse) Coordinates(opts ...FormatOption) [][2]float64 {
+	cfg := newFormatConfig(opts)
+	coords := make([][2]float64, 0, len(r.Results))
+	for i := range r.Results {
+		best, ok := r.Results[i].Best()
+		switch {
+		case ok:
+			coords = append(coords, [2]float64{cfg.round(best.Location.Lng), cfg.round(best.Location.Lat)})
+		case cfg.nanPlaceholders:
+			coords = append(coords, [2]float64{math.NaN(), math.NaN()})
+		}
+	}
+	return coords
+}
+
+// bestLocations collects the Best location of each query that has one
+func (r *BatchGeocodeResponse) bestLocations() []Location {
+	var locs []Location
+	for i := range r.Results {
+		if best, ok := r.Results[i].Best(); ok {
+			locs = append(locs, best.Location)
+		}
+	}
+	return locs
+}
+
+// Centroid returns the geographic center of locs: the mean of their unit
+// vectors on the sphere, projected back to lat/lng. Unlike averaging
+// degrees this is correct across the antimeridian and near the poles. It
+// returns false for an empty slice, or when the points cancel out (e.g.
+// two antipodes) and there is no meaningful center.
+func Centroid(locs []Location) (Location, bool) {
+	if len(locs) == 0 {
+		return Location{}, false
+	}
+	var x, y, z float64
+	for _, l := range locs {
+		lx, ly, lz := unitVector(l)
+		x, y, z = x+lx, y+ly, z+lz
+	}
+	n := float64(len(locs))
+	x, y, z = x/n, y/n, z/n
+	if math.Sqrt(x*x+y*y+z*z) < 1e-12 {
+		return Location{}, false
+	}
+	return Location{
+		Lat: math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi,
+		Lng: math.Atan2(y, x) * 180 / math.Pi,
+	}, true
+}
+
+// Interpolate returns the point a fraction of the way from a to b along the
+// great circle between them (spherical linear interpolation), e.g. 0.5 for
+// the midpoint. Fractions below 0 or above 1 are clamped, so the result
+// always lies between a and b: 0 gives a and 1 gives b. For antipodal
+// points there is no single great circle; Interpolate then returns a for
+// fractions below 0.5 and b otherwise.
+func Interpolate(a, b Location, fraction float64) Location {
+	switch {
+	case fraction <= 0 || math.IsNaN(fraction):
+		return a
+	case fraction >= 1:
+		return b
+	}
+	ax, ay, az := unitVector(a)
+	bx, by, bz := unitVector(b)
+	cx, cy, cz := ay*bz-az*by, az*bx-ax*bz, ax*by-ay*bx
+	sinD := math.Sqrt(cx*cx + cy*cy + cz*cz)
+	d := math.Atan2(sinD, ax*bx+ay*by+az*bz)
+	if sinD < 1e-12 {
+		if d > math.Pi/2 && fraction >= 0.5 { // antipodal
+			return b
+		}
+		return a
+	}
+	wa := math.Sin((1-fraction)*d) / sinD
+	wb := math.Sin(fraction*d) / sinD
+	x, y, z := wa*ax+wb*bx, wa*ay+wb*by, wa*az+wb*bz
+	return Location{
+		Lat: math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi,
+		Lng: math.Atan2(y, x) * 180 / math.Pi,
+	}
+}
+
+// unitVector returns l as a point on the unit sphere
+func unitVector(l Location) (x, y, z float64) {
+	lat := l.Lat * math.Pi / 180
+	lng := l.Lng * math.Pi / 180
+	return math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceToKnownCities(t *testing.T) {
+	newYork := Location{Lat: 40.7128, Lng: -74.0060}
+	losAngeles := Location{Lat: 34.0522, Lng: -118.2437}
+	london := Location{Lat: 51.5074, Lng: -0.1278}
+	paris := Location{Lat: 48.8566, Lng: 2.3522}
+	sydney := Location{Lat: -33.8688, Lng: 151.2093}
+
+	tests := []struct {
+		name   string
+		a, b   Location
+		wantKm float64
+	}{
+		{"new york to los angeles", newYork, losAngeles, 3936},
+		{"london to paris", london, paris, 344},
+		{"london to sydney", london, sydney, 16994},
+		{"same point", paris, paris, 0},
+	}
+	for _, tt := range tests {
+		got := tt.a.DistanceTo(tt.b) / 1000
+		if math.Abs(got-tt.wantKm) > 0.005*tt.wantKm+0.001 {
+			t.Errorf("%s: got %.1f km, want %.0f km", tt.name, got, tt.wantKm)
+		}
+		if back := tt.b.DistanceTo(tt.a) / 1000; math.Abs(back-got) > 1e-6 {
+			t.Errorf("%s: distance not symmetric: %f vs %f", tt.name, got, back)
+		}
+	}
+}
+
+func TestDistanceMiles(t *testing.T) {
+	london := Location{Lat: 51.5074, Lng: -0.1278}
+	paris := Location{Lat: 48.8566, Lng: 2.3522}
+	got := london.DistanceMiles(paris)
+	if math.Abs(got-213.7) > 1.5 {
+		t.Errorf("got %.1f miles, want about 213.7", got)
+	}
+}
+
+func TestDistanceUsesEarthRadius(t *testing.T) {
+	defer func(r float64) { EarthRadius = r }(EarthRadius)
+
+	a := Location{Lat: 0, Lng: 0}
+	b := Location{Lat: 0, Lng: 90}
+	EarthRadius = 1
+	if got := a.DistanceTo(b); math.Abs(got-math.Pi/2) > 1e-12 {
+		t.Errorf("got %f, want quarter circumference %f", got, math.Pi/2)
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	tests := []struct {
+		name string
+		locs []Location
+		want Location
+	}{
+		{"single point", []Location{{Lat: 48.8566, Lng: 2.3522}}, Location{Lat: 48.8566, Lng: 2.3522}},
+		{"equator pair", []Location{{Lat: 0, Lng: 10}, {Lat: 0, Lng: 20}}, Location{Lat: 0, Lng: 15}},
+		{"across dateline", []Location{{Lat: 0, Lng: 179}, {Lat: 0, Lng: -179}}, Location{Lat: 0, Lng: 180}},
+		{"across dateline off equator", []Location{{Lat: -17, Lng: 178}, {Lat: -15, Lng: -172}}, Location{Lat: -16.05, Lng: -177}},
+		{"around the pole", []Location{{Lat: 80, Lng: 0}, {Lat: 80, Lng: 90}, {Lat: 80, Lng: 180}, {Lat: 80, Lng: -90}}, Location{Lat: 90, Lng: 0}},
+	}
+	for _, tt := range tests {
+		got, ok := Centroid(tt.locs)
+		if !ok {
+			t.Errorf("%s: no centroid", tt.name)
+			continue
+		}
+		// Compare by distance so 180 and -180 count as the same meridian
+		if d := got.DistanceTo(tt.want); d > 10000 {
+			t.Errorf("%s: got %+v, want %+v (%.0f m away)", tt.name, got, tt.want, d)
+		}
+	}
+}
+
+func TestCentroidDegenerate(t *testing.T) {
+	if _, ok := Centroid(nil); ok {
+		t.Error("empty slice should have no centroid")
+	}
+	if _, ok := Centroid([]Location{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 180}}); ok {
+		t.Error("antipodal points should have no centroid")
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	london := Location{Lat: 51.5074, Lng: -0.1278}
+	newYork := Location{Lat: 40.7128, Lng: -74.0060}
+
+	if got := Interpolate(london, newYork, 0); got != london {
+		t.Errorf("fraction 0 = %+v, want %+v", got, london)
+	}
+	if got := Interpolate(london, newYork, 1); got != newYork {
+		t.Errorf("fraction 1 = %+v, want %+v", got, newYork)
+	}
+
+	mid := Interpolate(london, newYork, 0.5)
+	total := london.DistanceTo(newYork)
+	if d1, d2 := london.DistanceTo(mid), mid.DistanceTo(newYork); math.Abs(d1-total/2) > 1 || math.Abs(d2-total/2) > 1 {
+		t.Errorf("midpoint %+v is %.0f m and %.0f m from the ends, want %.0f m each", mid, d1, d2, total/2)
+	}
+	// The great circle bends north of both cities
+	if mid.Lat <= london.Lat {
+		t.Errorf("midpoint latitude %.2f, want north of London's %.2f", mid.Lat, london.Lat)
+	}
+
+	equatorMid := Interpolate(Location{Lat: 0, Lng: 0}, Location{Lat: 0, Lng: 90}, 0.5)
+	if math.Abs(equatorMid.Lat) > 1e-9 || math.Abs(equatorMid.Lng-45) > 1e-9 {
+		t.Errorf("equator midpoint = %+v, want {0 45}", equatorMid)
+	}
+}
+
+func TestInterpolateClampsFraction(t *testing.T) {
+	a, b := Location{Lat: 10, Lng: 10}, Location{Lat: 20, Lng: 20}
+	if got := Interpolate(a, b, -0.5); got != a {
+		t.Errorf("fraction -0.5 = %+v, want %+v", got, a)
+	}
+	if got := Interpolate(a, b, 1.5); got != b {
+		t.Errorf("fraction 1.5 = %+v, want %+v", got, b)
+	}
+}
+
+func TestBatchBoundingBoxAntimeridian(t *testing.T) {
+	result := func(lat, lng float64) GeocodeResponse {
+		return GeocodeResponse{Results: []GeocodeResult{{Location: Location{Lat: lat, Lng: lng}}}}
+	}
+	batch := &BatchGeocodeResponse{Results: []GeocodeResponse{
+		result(-18.14, 178.44),  // Suva, Fiji
+		{},                      // no match, ignored
+		result(-13.83, -171.76), // Apia, Samoa
+		result(-21.14, -175.2),  // Nuku'alofa, Tonga
+	}}
+
+	sw, ne, ok := batch.BoundingBoxAntimeridian()
+	if !ok {
+		t.Fatal("ok = false, want a box")
+	}
+	want := [2]Location{{Lat: -21.14, Lng: 178.44}, {Lat: -13.83, Lng: -171.76}}
+	if [2]Location{sw, ne} != want {
+		t.Errorf("BoundingBoxAntimeridian() = %v, %v; want %v, crossing 180°", sw, ne, want)
+	}
+	if sw, ne, _ := batch.BoundingBox(); sw.Lng != -175.2 || ne.Lng != 178.44 {
+		t.Errorf("BoundingBox() = %v, %v; want the plain box spanning the globe", sw, ne)
+	}
+	if _, _, ok := (&BatchGeocodeResponse{}).BoundingBoxAntimeridian(); ok {
+		t.Error("empty batch: ok = true")
+	}
+}
@@ -2,19 +2,239 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	apiKey  = "YOUR_API_KEY"
-	baseURL = "https://csv2geo.com/api/v1"
+	apiKey            = "YOUR_API_KEY"
+	baseURL           = "https://csv2geo.com/api"
+	defaultAPIVersion = "v1"
 )
 
+// Version is the version of this client, sent in the default User-Agent
+const Version = "0.1.0"
+
+// Client is a CSV2GEO API client. Each Client carries its own API key,
+// base URL and HTTP client, so several can be used side by side. Requests
+// go to BaseURL + "/" + APIVersion + path, e.g.
+// https://csv2geo.com/api/v1/geocode; an empty APIVersion is left out.
+type Client struct {
+	APIKey     string
+	BaseURL    string
+	APIVersion string
+	HTTPClient *http.Client
+
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryBudget    time.Duration
+	backoffFunc    BackoffFunc // set by WithBackoff
+
+	logger Logger
+	slog   *slog.Logger
+	debug  *debugWriter
+
+	cache     Cache
+	cacheTTL  time.Duration
+	timezones *timezoneCache // set alongside cache, see Timezone
+
+	limiter *tokenBucket
+	breaker *circuitBreaker
+	tracer  Tracer
+	metrics MetricsRecorder
+
+	compression    bool
+	strictDecoding bool
+
+	batchSize int
+	adaptive  *adaptiveBatch
+	userAgent string
+	header    http.Header // set by WithDefaultHeader
+
+	authMode AuthMode
+
+	clock clock
+
+	initErr error // first error from an Option, see Err
+
+	closeOnce sync.Once
+	closed    atomic.Bool
+
+	mu            sync.Mutex
+	lastRateLimit RateLimit
+}
+
+// Geocoder is the geocoding surface of Client. Depend on it instead of
+// *Client to substitute a fake in tests. It is kept deliberately small and
+// is stable: new features are added to Client, not to this interface.
+type Geocoder interface {
+	GeocodeContext(ctx context.Context, address string, opts ...CallOption) (*GeocodeResponse, error)
+	ReverseGeocodeContext(ctx context.Context, lat, lng float64, opts ...CallOption) (*GeocodeResponse, error)
+	BatchGeocodeContext(ctx context.Context, addresses []string, opts ...CallOption) (*BatchGeocodeResponse, error)
+}
+
+var _ Geocoder = (*Client)(nil)
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBaseURL points the client at a different API server (e.g. staging).
+// u is the root the version segment is appended to, e.g.
+// "https://staging.csv2geo.com/api" rather than ".../api/v1".
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		c.BaseURL = u
+	}
+}
+
+// apiVersionPattern matches API version segments such as "v1" or "v2"
+var apiVersionPattern = regexp.MustCompile(`^v\d+$`)
+
+// WithAPIVersion selects the API version, "v1" by default. A version not
+// of the form "v<number>" is reported by Err.
+func WithAPIVersion(v string) Option {
+	return func(c *Client) {
+		if !apiVersionPattern.MatchString(v) {
+			c.setInitErr(fmt.Errorf("invalid API version %q: want v<number>, e.g. v1", v))
+			return
+		}
+		c.APIVersion = v
+	}
+}
+
+// endpoint returns the full URL of path under the client's API version
+func (c *Client) endpoint(path string) string {
+	return c.BaseURL + c.versionedPath(path)
+}
+
+// versionedPath prefixes path with the client's API version, e.g.
+// "/v1/geocode", as the batch job API expects endpoints to be named
+func (c *Client) versionedPath(path string) string {
+	if c.APIVersion == "" {
+		return path
+	}
+	return "/" + c.APIVersion + path
+}
+
+// WithHTTPClient sets the HTTP client used for every request, e.g. one with
+// a Timeout or a proxy-aware Transport. As with net/http, a zero Timeout
+// means no timeout. A nil client leaves http.DefaultClient in place.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.HTTPClient = hc
+		}
+	}
+}
+
+// defaultBatchSize is how many addresses BatchGeocode sends per request
+// unless WithBatchSize says otherwise
+const defaultBatchSize = 100
+
+// WithBatchSize sets how many addresses BatchGeocode sends per request,
+// to stay under the server's payload limit
+func WithBatchSize(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
+// WithProxy sends requests through the proxy at proxyURL (http, https or
+// socks5), without building a whole http.Client. It keeps the Timeout of
+// any client set earlier with WithHTTPClient but replaces its Transport.
+// A malformed URL is reported by Err.
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			c.setInitErr(fmt.Errorf("invalid proxy URL %q", proxyURL))
+			return
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(u)
+		hc := *c.HTTPClient
+		hc.Transport = transport
+		c.HTTPClient = &hc
+	}
+}
+
+// WithUserAgent replaces the default "csv2geo-go/<Version>" User-Agent
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// NewClient creates a client for the given API key
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		APIKey:     apiKey,
+		BaseURL:    baseURL,
+		APIVersion: defaultAPIVersion,
+		HTTPClient: http.DefaultClient,
+		batchSize:  defaultBatchSize,
+		userAgent:  "csv2geo-go/" + Version,
+		clock:      realClock{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if lru, ok := c.cache.(*LRUCache); ok {
+		lru.useClock(c.clock)
+	}
+	if c.cache != nil {
+		c.timezones = newTimezoneCache()
+	}
+	return c
+}
+
+// NewClientFromEnv creates a client from the CSV2GEO_API_KEY environment
+// variable and, if set, CSV2GEO_BASE_URL. It fails if the key is empty.
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	key := os.Getenv("CSV2GEO_API_KEY")
+	if key == "" {
+		return nil, errors.New("CSV2GEO_API_KEY is not set")
+	}
+	if u := os.Getenv("CSV2GEO_BASE_URL"); u != "" {
+		opts = append([]Option{WithBaseURL(u)}, opts...)
+	}
+	c := NewClient(key, opts...)
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Err reports a configuration error from the options passed to NewClient,
+// such as a malformed WithProxy URL. A client with a non-nil Err fails
+// every request with that error, so check it right after construction.
+func (c *Client) Err() error {
+	return c.initErr
+}
+
+// setInitErr records the first option error
+func (c *Client) setInitErr(err error) {
+	if c.initErr == nil {
+		c.initErr = err
+	}
+}
+
+// defaultClient backs the package-level helper functions
+var defaultClient = NewClient(apiKey)
+
 // Location represents a geographic coordinate
 type Location struct {
 	Lat float64 `json:"lat"`
@@ -31,69 +251,131 @@ type AddressComponents struct {
 	Country     string `json:"country"`
 }
 
-// GeocodeResult represents a single geocoding result
+// GeocodeResult represents a single geocoding result. Confidence is the
+// API's match score from 0 to 1; it is 0 when the API omits it.
+// Interpolated is set when the server estimated the point along a street
+// segment rather than matching a known address point; it is false when the
+// API omits it.
 type GeocodeResult struct {
 	FormattedAddress string            `json:"formatted_address"`
 	Location         Location          `json:"location"`
-	Accuracy         string            `json:"accuracy"`
+	Accuracy         Accuracy          `json:"accuracy"`
+	Confidence       float64           `json:"confidence"`
+	Interpolated     bool              `json:"interpolated,omitempty"`
 	Components       AddressComponents `json:"components"`
 }
 
-// GeocodeResponse is the API response for geocoding
+// GeocodeResponse is the API response for geocoding. TotalResults and
+// HasMore are only reported for paginated requests (see WithPage).
+//
+// Duration is the round trip of the request that produced the response,
+// from sending it until its body was read, retries and backoff included.
+// DecodeDuration is the time then spent decoding the JSON locally. Both
+// are zero for cache hits and for responses from a batch.
 type GeocodeResponse struct {
-	Query   string          `json:"query"`
-	Results []GeocodeResult `json:"results"`
+	Query        string          `json:"query"`
+	Results      []GeocodeResult `json:"results"`
+	TotalResults int             `json:"total_results,omitempty"`
+	HasMore      bool            `json:"has_more,omitempty"`
+
+	Duration       time.Duration `json:"-"`
+	DecodeDuration time.Duration `json:"-"`
+}
+
+// recordTiming implements timed
+func (r *GeocodeResponse) recordTiming(roundTrip, decode time.Duration) {
+	r.Duration = roundTrip
+	r.DecodeDuration = decode
+}
+
+// Geocode converts an address to coordinates using the default client
+func Geocode(address string, opts ...CallOption) (*GeocodeResponse, error) {
+	return defaultClient.Geocode(address, opts...)
 }
 
 // Geocode converts an address to coordinates
-func Geocode(address string) (*GeocodeResponse, error) {
-	params := url.Values{}
-	params.Add("q", address)
-	params.Add("api_key", apiKey)
+func (c *Client) Geocode(address string, opts ...CallOption) (*GeocodeResponse, error) {
+	return c.GeocodeContext(context.Background(), address, opts...)
+}
 
-	resp, err := http.Get(fmt.Sprintf("%s/geocode?%s", baseURL, params.Encode()))
+// GeocodeContext converts an address to coordinates, honoring ctx
+// cancellation. An address that matches nothing gives an empty Results
+// slice, not an error; an empty or whitespace-only address fails with
+// ErrEmptyQuery without making a request.
+func (c *Client) GeocodeContext(ctx context.Context, address string, opts ...CallOption) (*GeocodeResponse, error) {
+	if strings.TrimSpace(address) == "" {
+		return nil, ErrEmptyQuery
+	}
+	cfg, err := newCallConfig(opts)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	cfg.params.Add("q", address)
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("geocoding failed: %s", string(body))
-	}
+	return c.getGeocode(ctx, cfg, "/geocode", "geocoding failed")
+}
 
-	var result GeocodeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+// GeocodeStructured geocodes an address given as separate components using the default client
+func GeocodeStructured(ctx context.Context, comp AddressComponents, opts ...CallOption) (*GeocodeResponse, error) {
+	return defaultClient.GeocodeStructured(ctx, comp, opts...)
+}
+
+// GeocodeStructured geocodes an address given as separate components. Each
+// non-empty component is sent as its own street, city, state, postcode or
+// country parameter instead of one free-text q, which avoids misparsing
+// well-structured data. HouseNumber is prefixed to Street. If every
+// component is empty or whitespace, ErrEmptyQuery is returned and nothing
+// is sent.
+func (c *Client) GeocodeStructured(ctx context.Context, comp AddressComponents, opts ...CallOption) (*GeocodeResponse, error) {
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	street := strings.TrimSpace(comp.HouseNumber + " " + comp.Street)
+	empty := true
+	for _, p := range []struct{ key, value string }{
+		{"street", street},
+		{"city", comp.City},
+		{"state", comp.State},
+		{"postcode", comp.Postcode},
+		{"country", comp.Country},
+	} {
+		if v := strings.TrimSpace(p.value); v != "" {
+			cfg.params.Set(p.key, v)
+			empty = false
+		}
+	}
+	if empty {
+		return nil, ErrEmptyQuery
 	}
 
-	return &result, nil
+	return c.getGeocode(ctx, cfg, "/geocode", "structured geocoding failed")
 }
 
-// ReverseGeocode converts coordinates to an address
-func ReverseGeocode(lat, lng float64) (*GeocodeResponse, error) {
-	params := url.Values{}
-	params.Add("lat", fmt.Sprintf("%f", lat))
-	params.Add("lng", fmt.Sprintf("%f", lng))
-	params.Add("api_key", apiKey)
+// ReverseGeocode converts coordinates to an address using the default client
+func ReverseGeocode(lat, lng float64, opts ...CallOption) (*GeocodeResponse, error) {
+	return defaultClient.ReverseGeocode(lat, lng, opts...)
+}
 
-	resp, err := http.Get(fmt.Sprintf("%s/reverse?%s", baseURL, params.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// ReverseGeocode converts coordinates to an address
+func (c *Client) ReverseGeocode(lat, lng float64, opts ...CallOption) (*GeocodeResponse, error) {
+	return c.ReverseGeocodeContext(context.Background(), lat, lng, opts...)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("reverse geocoding failed: %s", string(body))
+// ReverseGeocodeContext converts coordinates to an address, honoring ctx cancellation
+func (c *Client) ReverseGeocodeContext(ctx context.Context, lat, lng float64, opts ...CallOption) (*GeocodeResponse, error) {
+	if err := validateCoordinate(lat, lng); err != nil {
+		return nil, err
 	}
-
-	var result GeocodeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return nil, err
 	}
+	cfg.params.Add("lat", fmt.Sprintf("%f", lat))
+	cfg.params.Add("lng", fmt.Sprintf("%f", lng))
+	cfg.applyRadius()
 
-	return &result, nil
+	return c.getGeocode(ctx, cfg, "/reverse", "reverse geocoding failed")
 }
 
 // BatchGeocodeRequest is the request body for batch geocoding
@@ -101,46 +383,294 @@ type BatchGeocodeRequest struct {
 	Addresses []string `json:"addresses"`
 }
 
-// BatchGeocodeResponse is the response for batch geocoding
+// BatchError describes one address the API could not geocode in a batch
+type BatchError struct {
+	Index   int    `json:"index"`
+	Query   string `json:"query"`
+	Message string `json:"message"`
+}
+
+// BatchGeocodeResponse is the response for batch geocoding. Errors lists the
+// addresses that failed when the API reports a partial failure.
 type BatchGeocodeResponse struct {
 	Results []GeocodeResponse `json:"results"`
+	Errors  []BatchError      `json:"errors,omitempty"`
+}
+
+// BatchGeocode geocodes multiple addresses using the default client
+func BatchGeocode(addresses []string, opts ...CallOption) (*BatchGeocodeResponse, error) {
+	return defaultClient.BatchGeocode(addresses, opts...)
 }
 
 // BatchGeocode geocodes multiple addresses
-func BatchGeocode(addresses []string) (*BatchGeocodeResponse, error) {
-	reqBody, err := json.Marshal(BatchGeocodeRequest{Addresses: addresses})
+func (c *Client) BatchGeocode(addresses []string, opts ...CallOption) (*BatchGeocodeResponse, error) {
+	return c.BatchGeocodeContext(context.Background(), addresses, opts...)
+}
+
+// BatchGeocodeContext geocodes multiple addresses, honoring ctx
+// cancellation. Inputs larger than the batch size (see WithBatchSize) are
+// split into chunks sent one after another, and the results concatenated
+// in input order. If some chunks fail the rest are still sent: the
+// response keeps a placeholder and a BatchError for each address of a
+// failed chunk, and the returned error joins one *ChunkError per failed
+// chunk. Cancelling ctx, or its deadline passing, aborts the chunk in
+// flight, even mid-response, and sends no more; the error then wraps
+// ctx.Err() rather than a *ChunkError. If any address is empty or
+// whitespace-only, nothing is sent and the error wraps ErrEmptyQuery and
+// lists the offending indices.
+func (c *Client) BatchGeocodeContext(ctx context.Context, addresses []string, opts ...CallOption) (*BatchGeocodeResponse, error) {
+	if err := checkEmptyQueries(addresses); err != nil {
+		return nil, err
+	}
+	cfg, err := newCallConfig(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
+	query := func(i int) string { return addresses[i] }
+	send := func(ctx context.Context, cfg *callConfig, start, end int) (*BatchGeocodeResponse, error) {
+		return c.batchChunk(ctx, cfg, addresses[start:end])
+	}
+	return c.sendChunks(ctx, cfg, len(addresses), query, send)
+}
 
-	req, err := http.NewRequest("POST", baseURL+"/geocode", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// checkEmptyQueries returns an error wrapping ErrEmptyQuery and listing
+// the indices of any empty or whitespace-only addresses
+func checkEmptyQueries(addresses []string) error {
+	var empty []int
+	for i, a := range addresses {
+		if strings.TrimSpace(a) == "" {
+			empty = append(empty, i)
+		}
+	}
+	if len(empty) > 0 {
+		return fmt.Errorf("%w at index %s", ErrEmptyQuery, joinInts(empty))
+	}
+	return nil
+}
+
+// joinInts formats indices as "1, 4, 7"
+func joinInts(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sendChunks splits n batch inputs into chunks of the batch size, or of the
+// size WithAdaptiveBatch arrives at, and sends them one after another with
+// send, as described for BatchGeocodeContext.
+// query names input i for the placeholders of a failed chunk.
+func (c *Client) sendChunks(ctx context.Context, cfg *callConfig, n int, query func(i int) string,
+	send func(ctx context.Context, cfg *callConfig, start, end int) (*BatchGeocodeResponse, error)) (*BatchGeocodeResponse, error) {
+	ctx, cancel := cfg.withDeadline(ctx)
+	defer cancel()
+
+	tuner := c.newBatchTuner()
+	key := cfg.idempotencyKey
+	if key == "" && c.maxRetries > 0 {
+		var err error
+		if key, err = newUUID(); err != nil {
+			return nil, fmt.Errorf("failed to generate idempotency key: %w", err)
+		}
+	}
+	result := &BatchGeocodeResponse{Results: make([]GeocodeResponse, 0, n)}
+	var chunkErrs []error
+	for start, end := 0, 0; start < n; start = end {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		end = start + tuner.size
+		if end > n {
+			end = n
+		}
+		chunkCfg := *cfg
+		chunkCfg.idempotencyKey = key
+		if key != "" && (start > 0 || end < n) {
+			chunkCfg.idempotencyKey = key + ":" + strconv.Itoa(start)
+		}
+		sent := tuner.now()
+		chunk, err := send(ctx, &chunkCfg, start, end)
+		failed := end - start
+		if err == nil {
+			failed = len(chunk.Errors)
+		}
+		tuner.observe(tuner.now().Sub(sent), end-start, failed, err)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return result, err
+			}
+			chunkErrs = append(chunkErrs, &ChunkError{Start: start, End: end, Err: err})
+			for i := start; i < end; i++ {
+				result.Results = append(result.Results, GeocodeResponse{Query: query(i)})
+				result.Errors = append(result.Errors, BatchError{Index: i, Query: query(i), Message: err.Error()})
+			}
+			continue
+		}
+		result.Results = append(result.Results, chunk.Results...)
+		for _, e := range chunk.Errors {
+			e.Index += start
+			result.Errors = append(result.Errors, e)
+		}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return result, errors.Join(chunkErrs...)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// batchChunk sends one batch request
+func (c *Client) batchChunk(ctx context.Context, cfg *callConfig, addresses []string) (*BatchGeocodeResponse, error) {
+	var result BatchGeocodeResponse
+	if err := c.postJSON(ctx, cfg, "/geocode", BatchGeocodeRequest{Addresses: addresses}, "batch geocoding failed", &result); err != nil {
+		return nil, err
+	}
+	// Fill in the query text if the server only reported the index
+	for i := range result.Errors {
+		e := &result.Errors[i]
+		if e.Query == "" && e.Index >= 0 && e.Index < len(addresses) {
+			e.Query = addresses[e.Index]
+		}
+	}
+	return &result, nil
+}
+
+// BatchReverseGeocode reverse geocodes multiple points using the default
+// client
+func BatchReverseGeocode(ctx context.Context, points []Location, opts ...CallOption) (*BatchGeocodeResponse, error) {
+	return defaultClient.BatchReverseGeocode(ctx, points, opts...)
+}
+
+// BatchReverseGeocode converts multiple points to addresses, returning one
+// response per point in input order. Each response's Query is the point as
+// "lat,lng". Every point is validated first, as ReverseGeocode does, and
+// an invalid one fails the whole call with ErrInvalidCoordinate before any
+// request is made. Chunking and per-point errors work as for
+// BatchGeocodeContext.
+func (c *Client) BatchReverseGeocode(ctx context.Context, points []Location, opts ...CallOption) (*BatchGeocodeResponse, error) {
+	for i, p := range points {
+		if err := validateCoordinate(p.Lat, p.Lng); err != nil {
+			return nil, fmt.Errorf("point %d: %w", i, err)
+		}
+	}
+	cfg, err := newCallConfig(opts)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
+	}
+	cfg.applyRadius()
+	query := func(i int) string { return pointQuery(points[i]) }
+	send := func(ctx context.Context, cfg *callConfig, start, end int) (*BatchGeocodeResponse, error) {
+		return c.reverseChunk(ctx, cfg, points[start:end])
+	}
+	return c.sendChunks(ctx, cfg, len(points), query, send)
+}
+
+// BatchReverseRequest is the request body for batch reverse geocoding
+type BatchReverseRequest struct {
+	Coordinates []Location `json:"coordinates"`
+}
+
+// batchReverseResponse is the wire form of a batch reverse response, whose
+// queries are points rather than strings
+type batchReverseResponse struct {
+	Results []struct {
+		Query   Location        `json:"query"`
+		Results []GeocodeResult `json:"results"`
+	} `json:"results"`
+	Errors []BatchError `json:"errors,omitempty"`
+}
+
+// reverseChunk sends one batch reverse request
+func (c *Client) reverseChunk(ctx context.Context, cfg *callConfig, points []Location) (*BatchGeocodeResponse, error) {
+	var raw batchReverseResponse
+	if err := c.postJSON(ctx, cfg, "/reverse", BatchReverseRequest{Coordinates: points}, "batch reverse geocoding failed", &raw); err != nil {
+		return nil, err
+	}
+	result := &BatchGeocodeResponse{Results: make([]GeocodeResponse, len(raw.Results)), Errors: raw.Errors}
+	for i, r := range raw.Results {
+		q := r.Query
+		if i < len(points) {
+			q = points[i] // the server may echo a rounded point, or none
+		}
+		result.Results[i] = GeocodeResponse{Query: pointQuery(q), Results: r.Results, TotalResults: len(r.Results)}
+	}
+	for i := range result.Errors {
+		e := &result.Errors[i]
+		if e.Query == "" && e.Index >= 0 && e.Index < len(points) {
+			e.Query = pointQuery(points[e.Index])
+		}
 	}
-	defer resp.Body.Close()
+	return result, nil
+}
+
+// pointQuery renders p as the Query of a reverse geocode response
+func pointQuery(p Location) string {
+	return formatCoord(p.Lat) + "," + formatCoord(p.Lng)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("batch geocoding failed: %s", string(body))
+// getGeocode fetches a GeocodeResponse from path, going through the cache
+// when WithCache is set
+func (c *Client) getGeocode(ctx context.Context, cfg *callConfig, path, failMsg string) (*GeocodeResponse, error) {
+	var key string
+	if c.cache != nil {
+		key = c.cacheKey(path, cfg)
+		if cached, ok := c.cache.Get(key); ok {
+			hit := cloneResponse(cached)
+			hit.recordTiming(0, 0)
+			return hit, nil
+		}
 	}
 
-	var result BatchGeocodeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	var result GeocodeResponse
+	var err error
+	if cfg.post && path == "/geocode" && cfg.params.Has("q") {
+		err = c.postGeocode(ctx, cfg, failMsg, &result)
+	} else {
+		err = c.getJSON(ctx, cfg, path, failMsg, &result)
+	}
+	if err != nil {
+		return nil, err
 	}
 
+	if c.cache != nil {
+		c.cache.Set(key, cloneResponse(&result), c.cacheTTL)
+	}
 	return &result, nil
 }
 
+// postGeocode sends the forward geocode in cfg as a one-address batch, for
+// WithPostMethod. Like the batch calls it generates an idempotency key when
+// retries are on and the caller gave none, so that 5xx can be retried.
+func (c *Client) postGeocode(ctx context.Context, cfg *callConfig, failMsg string, out *GeocodeResponse) error {
+	postCfg := *cfg
+	postCfg.params = url.Values{}
+	for k, v := range cfg.params {
+		if k != "q" {
+			postCfg.params[k] = v
+		}
+	}
+	if postCfg.idempotencyKey == "" && c.maxRetries > 0 {
+		key, err := newUUID()
+		if err != nil {
+			return fmt.Errorf("failed to generate idempotency key: %w", err)
+		}
+		postCfg.idempotencyKey = key
+	}
+	address := cfg.params.Get("q")
+	var batch BatchGeocodeResponse
+	if err := c.postJSON(ctx, &postCfg, "/geocode", BatchGeocodeRequest{Addresses: []string{address}}, failMsg, &batch); err != nil {
+		return err
+	}
+	if len(batch.Errors) > 0 {
+		return fmt.Errorf("%s: %s", failMsg, batch.Errors[0].Message)
+	}
+	if len(batch.Results) > 0 {
+		*out = batch.Results[0]
+	}
+	if out.Query == "" {
+		out.Query = address
+	}
+	return nil
+}
+
 func main() {
 	// Forward geocoding
 	fmt.Println("Forward Geocoding:")
@@ -149,9 +679,14 @@ func main() {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	fmt.Printf("  Address: %s\n", result.Results[0].FormattedAddress)
-	fmt.Printf("  Lat: %f\n", result.Results[0].Location.Lat)
-	fmt.Printf("  Lng: %f\n", result.Results[0].Location.Lng)
+	best, err := result.BestResult()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("  Address: %s\n", best.FormattedAddress)
+	fmt.Printf("  Lat: %f\n", best.Location.Lat)
+	fmt.Printf("  Lng: %f\n", best.Location.Lng)
 
 	// Reverse geocoding
 	fmt.Println("\nReverse Geocoding:")
@@ -160,7 +695,11 @@ func main() {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	fmt.Printf("  Address: %s\n", reverseResult.Results[0].FormattedAddress)
+	if best, err := reverseResult.BestResult(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	} else {
+		fmt.Printf("  Address: %s\n", best.FormattedAddress)
+	}
 
 	// Batch geocoding
 	addresses := []string{
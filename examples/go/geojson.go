@@ -0,0 +1,72 @@
+// CSV2GEO API - Go Geocoding Example: GeoJSON output
+package main
+
+import "encoding/json"
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoJSONPoint      `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+// geoJSONProperties are a feature's properties. A struct rather than a map
+// so they marshal in this documented order, query first, and output diffs
+// cleanly when fields are added.
+type geoJSONProperties struct {
+	Query            string   `json:"query"`
+	FormattedAddress string   `json:"formatted_address"`
+	Accuracy         Accuracy `json:"accuracy"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"` // [lng, lat] per RFC 7946
+}
+
+// newFeature converts a result to a Point feature
+func newFeature(query string, res GeocodeResult, cfg formatConfig) geoJSONFeature {
+	return geoJSONFeature{
+		Type: "Feature",
+		Geometry: geoJSONPoint{
+			Type:        "Point",
+			Coordinates: [2]float64{cfg.round(res.Location.Lng), cfg.round(res.Location.Lat)},
+		},
+		Properties: geoJSONProperties{
+			Query:            query,
+			FormattedAddress: res.FormattedAddress,
+			Accuracy:         res.Accuracy,
+		},
+	}
+}
+
+// ToGeoJSON returns the results as a GeoJSON FeatureCollection with one
+// Point feature per result. Coordinates are rounded to 6 decimals unless
+// WithCoordinatePrecision says otherwise. Each feature's properties are
+// query, formatted_address and accuracy, always in that order, so the same
+// response yields the same bytes.
+func (r *GeocodeResponse) ToGeoJSON(opts ...FormatOption) ([]byte, error) {
+	cfg := newFormatConfig(opts)
+	fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for _, res := range r.Results {
+		fc.Features = append(fc.Features, newFeature(r.Query, res, cfg))
+	}
+	return json.Marshal(fc)
+}
+
+// ToGeoJSONFeatureCollection returns every result of every query in the
+// batch as a single GeoJSON FeatureCollection, formatted as by ToGeoJSON
+func (r *BatchGeocodeResponse) ToGeoJSONFeatureCollection(opts ...FormatOption) ([]byte, error) {
+	cfg := newFormatConfig(opts)
+	fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for _, resp := range r.Results {
+		for _, res := range resp.Results {
+			fc.Features = append(fc.Features, newFeature(resp.Query, res, cfg))
+		}
+	}
+	return json.Marshal(fc)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestToGeoJSONStableOutput(t *testing.T) {
+	resp := &GeocodeResponse{
+		Query: "1600 Pennsylvania Ave",
+		Results: []GeocodeResult{{
+			FormattedAddress: "1600 Pennsylvania Ave NW, Washington, DC 20500",
+			Location:         Location{Lat: 38.8976763, Lng: -77.0365298},
+			Accuracy:         AccuracyRooftop,
+		}},
+	}
+	want := `{"type":"FeatureCollection","features":[{"type":"Feature",` +
+		`"geometry":{"type":"Point","coordinates":[-77.03653,38.897676]},` +
+		`"properties":{"query":"1600 Pennsylvania Ave",` +
+		`"formatted_address":"1600 Pennsylvania Ave NW, Washington, DC 20500",` +
+		`"accuracy":"rooftop"}}]}`
+
+	first, err := resp.ToGeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != want {
+		t.Fatalf("ToGeoJSON() =\n%s\nwant\n%s", first, want)
+	}
+	for i := 0; i < 20; i++ {
+		again, err := resp.ToGeoJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again, first) {
+			t.Fatalf("call %d differs:\n%s\nfirst:\n%s", i, again, first)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// CSV2GEO API - Go Geocoding Example: custom request headers
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// reservedHeaders are set by the client itself, from the API key or other
+// options. WithHeader and WithDefaultHeader refuse them; WithReservedHeader
+// overrides them deliberately.
+var reservedHeaders = map[string]string{
+	"Authorization":    "WithAuthMode",
+	"X-Api-Key":        "WithAuthMode",
+	"User-Agent":       "WithUserAgent",
+	"Idempotency-Key":  "WithIdempotencyKey",
+	"Accept-Encoding":  "WithCompression",
+	"Content-Encoding": "WithCompression",
+	"Content-Type":     "",
+	"Content-Length":   "",
+	"Host":             "",
+}
+
+// WithHeader adds a header to the request, e.g. an X-Tenant-ID that a
+// gateway in front of the API requires. It adds rather than replaces, so
+// repeating it sends several headers, or several values of one. Headers
+// the client manages itself, such as Authorization, User-Agent or
+// Content-Type, are refused; see WithReservedHeader.
+func WithHeader(key, value string) CallOption {
+	return func(cfg *callConfig) error {
+		if err := checkHeader(key, value, false); err != nil {
+			return err
+		}
+		cfg.header.Add(key, value)
+		return nil
+	}
+}
+
+// WithReservedHeader is WithHeader for the headers it refuses. The value
+// replaces the one the client would send, e.g. an Authorization header
+// for a gateway that takes its own token in place of the API key. The
+// client's own logging still redacts the API key, but not other secrets
+// passed this way.
+func WithReservedHeader(key, value string) CallOption {
+	return func(cfg *callConfig) error {
+		if err := checkHeader(key, value, true); err != nil {
+			return err
+		}
+		cfg.header.Add(key, value)
+		return nil
+	}
+}
+
+// WithDefaultHeader adds a header to every request the client makes, as
+// WithHeader does for one call. Headers a call sets with WithHeader
+// replace a default of the same name. A reserved or malformed header is
+// reported by Err.
+func WithDefaultHeader(key, value string) Option {
+	return func(c *Client) {
+		if err := checkHeader(key, value, false); err != nil {
+			c.setInitErr(err)
+			return
+		}
+		if c.header == nil {
+			c.header = http.Header{}
+		}
+		c.header.Add(key, value)
+	}
+}
+
+// checkHeader rejects malformed header names and values, and reserved
+// names unless allowReserved
+func checkHeader(key, value string, allowReserved bool) error {
+	if key == "" || strings.ContainsAny(key, " \t\r\n:") {
+		return fmt.Errorf("invalid header name %q", key)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("invalid value for header %s: contains a line break", key)
+	}
+	canonical := http.CanonicalHeaderKey(key)
+	if alt, reserved := reservedHeaders[canonical]; reserved && !allowReserved {
+		if alt != "" {
+			return fmt.Errorf("header %s is set by the client; use %s, or WithReservedHeader to override it", canonical, alt)
+		}
+		return fmt.Errorf("header %s is set by the client; use WithReservedHeader to override it", canonical)
+	}
+	return nil
+}
+
+// applyDefaultHeaders adds the WithDefaultHeader headers that the call did
+// not set itself
+func (c *Client) applyDefaultHeaders(req *http.Request) {
+	for k, vs := range c.header {
+		if _, ok := req.Header[k]; !ok {
+			req.Header[k] = append([]string(nil), vs...)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithHeader(t *testing.T) {
+	var got http.Header
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		RespondGeocode()(w, r)
+	}), WithDefaultHeader("X-Tenant-ID", "acme"), WithDefaultHeader("X-Env", "prod"))
+	defer srv.Close()
+
+	_, err := c.GeocodeContext(context.Background(), "1600 Pennsylvania Ave",
+		WithHeader("X-Env", "staging"), WithHeader("X-Trace", "a"), WithHeader("X-Trace", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := got.Get("X-Tenant-ID"); v != "acme" {
+		t.Errorf("X-Tenant-ID = %q, want the client default", v)
+	}
+	if v := got.Values("X-Env"); !reflect.DeepEqual(v, []string{"staging"}) {
+		t.Errorf("X-Env = %q, want the call's value to replace the default", v)
+	}
+	if v := got.Values("X-Trace"); !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("X-Trace = %q, want both values", v)
+	}
+	if v := got.Get("Authorization"); v != "Bearer test_key" {
+		t.Errorf("Authorization = %q", v)
+	}
+
+	if _, err := c.GeocodeContext(context.Background(), "x", WithHeader("authorization", "Bearer other")); err == nil || !strings.Contains(err.Error(), "WithAuthMode") {
+		t.Errorf("reserved header: err = %v, want a refusal pointing at WithAuthMode", err)
+	}
+	if c := NewClient("k", WithDefaultHeader("User-Agent", "x")); c.Err() == nil {
+		t.Error("WithDefaultHeader(User-Agent) did not set Err")
+	}
+	if _, err := c.GeocodeContext(context.Background(), "x", WithReservedHeader("Authorization", "Token gateway")); err != nil {
+		t.Fatal(err)
+	}
+	if v := got.Values("Authorization"); !reflect.DeepEqual(v, []string{"Token gateway"}) {
+		t.Errorf("Authorization = %q, want the WithReservedHeader value only", v)
+	}
+}
+
+func TestCacheKeyedByHeaders(t *testing.T) {
+	calls := 0
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		RespondGeocode(GeocodeResult{FormattedAddress: "tenant " + r.Header.Get("X-Tenant-ID")})(w, r)
+	}), WithCache(NewLRUCache(10), 0))
+	defer srv.Close()
+
+	geocode := func(tenant string) string {
+		t.Helper()
+		resp, err := c.GeocodeContext(context.Background(), "1 Main St", WithHeader("X-Tenant-ID", tenant))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Results[0].FormattedAddress
+	}
+	if got := geocode("A"); got != "tenant A" {
+		t.Errorf("tenant A got %q", got)
+	}
+	if got := geocode("B"); got != "tenant B" {
+		t.Errorf("tenant B got %q, want its own response rather than A's cached one", got)
+	}
+	if got := geocode("A"); got != "tenant A" || calls != 2 {
+		t.Errorf("tenant A again got %q after %d requests, want a cache hit", got, calls)
+	}
+}
@@ -0,0 +1,164 @@
+// CSV2GEO API - Go Geocoding Example: async batch jobs
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// JobStatus is the state of an async batch job
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobDone      JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Terminal reports whether the job has stopped and its results are final
+func (s JobStatus) Terminal() bool {
+	return s == JobDone || s == JobFailed || s == JobCancelled
+}
+
+// ErrJobNotFinished is returned by DownloadJobResults for a job that is
+// still pending or running
+var ErrJobNotFinished = errors.New("csv2geo: batch job not finished")
+
+// jobInput is one entry of BatchCreateRequest.inputs in openapi.yaml
+type jobInput struct {
+	ID     string            `json:"id"`
+	Params map[string]string `json:"params"`
+}
+
+type jobCreateRequest struct {
+	API    string     `json:"api"`
+	Inputs []jobInput `json:"inputs"`
+}
+
+type jobCreateResponse struct {
+	ID     string    `json:"id"`
+	Status JobStatus `json:"status"`
+}
+
+// jobResponse mirrors BatchGetResponse in openapi.yaml
+type jobResponse struct {
+	ID      string           `json:"id"`
+	Status  JobStatus        `json:"status"`
+	Results []jobResultEntry `json:"results"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type jobResultEntry struct {
+	InputID string                 `json:"input_id"`
+	Status  int                    `json:"status"`
+	Result  json.RawMessage        `json:"result"`
+	Query   map[string]interface{} `json:"query"`
+}
+
+// SubmitBulkJob queues addresses for geocoding with the async /batch
+// endpoint and returns the job ID. Use it when BatchGeocode would be too
+// large for one synchronous request. If any address is empty or
+// whitespace-only, nothing is sent and the error wraps ErrEmptyQuery and
+// lists the offending indices.
+func (c *Client) SubmitBulkJob(ctx context.Context, addresses []string) (string, error) {
+	if err := checkEmptyQueries(addresses); err != nil {
+		return "", err
+	}
+	body := jobCreateRequest{API: c.versionedPath("/geocode"), Inputs: make([]jobInput, len(addresses))}
+	for i, addr := range addresses {
+		body.Inputs[i] = jobInput{ID: strconv.Itoa(i), Params: map[string]string{"q": addr}}
+	}
+
+	cfg, _ := newCallConfig(nil)
+	var created jobCreateResponse
+	if err := c.postJSON(ctx, cfg, "/batch", body, "submitting batch job failed", &created); err != nil {
+		return "", err
+	}
+	if created.ID == "" {
+		return "", errors.New("submitting batch job failed: no job id in response")
+	}
+	return created.ID, nil
+}
+
+// getJob fetches the job descriptor
+func (c *Client) getJob(ctx context.Context, jobID string) (*jobResponse, error) {
+	cfg, _ := newCallConfig(nil)
+	var job jobResponse
+	if err := c.getJSON(ctx, cfg, "/batch/"+url.PathEscape(jobID), "fetching batch job failed", &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJobStatus returns the current state of a batch job
+func (c *Client) GetJobStatus(ctx context.Context, jobID string) (JobStatus, error) {
+	job, err := c.getJob(ctx, jobID)
+	if err != nil {
+		return "", err
+	}
+	return job.Status, nil
+}
+
+// DownloadJobResults returns one GeocodeResponse per submitted address, in
+// submission order. An address the API failed on gets a response with its
+// query and no results. It returns ErrJobNotFinished until the job is in a
+// terminal state.
+func (c *Client) DownloadJobResults(ctx context.Context, jobID string) ([]GeocodeResponse, error) {
+	job, err := c.getJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !job.Status.Terminal() {
+		return nil, fmt.Errorf("%w: status %s", ErrJobNotFinished, job.Status)
+	}
+
+	entries := append([]jobResultEntry(nil), job.Results...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, _ := strconv.Atoi(entries[i].InputID)
+		b, _ := strconv.Atoi(entries[j].InputID)
+		return a < b
+	})
+
+	results := make([]GeocodeResponse, len(entries))
+	for i, e := range entries {
+		results[i].Query, _ = e.Query["q"].(string)
+		if e.Status < 200 || e.Status > 299 || len(e.Result) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(e.Result, &results[i]); err != nil {
+			return nil, fmt.Errorf("failed to decode result for input %s: %w", e.InputID, err)
+		}
+	}
+	return results, nil
+}
+
+// WaitForJob polls the job every pollInterval until it reaches a terminal
+// state, returning that state, or until ctx is done
+func (c *Client) WaitForJob(ctx context.Context, jobID string, pollInterval time.Duration) (JobStatus, error) {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	for {
+		status, err := c.GetJobStatus(ctx, jobID)
+		if err != nil {
+			return "", err
+		}
+		if status.Terminal() {
+			return status, nil
+		}
+		if err := c.clock.Sleep(ctx, pollInterval); err != nil {
+			return status, err
+		}
+	}
+}
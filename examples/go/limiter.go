@@ -0,0 +1,71 @@
+// CSV2GEO API - Go Geocoding Example: client-side rate limiting
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithRateLimit caps outgoing requests, retries included, at
+// requestsPerSecond on average with bursts of up to burst requests. A
+// request waiting for its turn gives up when its context is done.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *Client) {
+		if requestsPerSecond <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newTokenBucket(requestsPerSecond, burst)
+	}
+}
+
+// tokenBucket is a token-bucket limiter. Tokens are refilled lazily from
+// the elapsed time, so it needs no background goroutine.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done. The bucket
+// starts full at the first call.
+func (b *tokenBucket) Wait(ctx context.Context, clk clock) error {
+	for {
+		b.mu.Lock()
+		now := clk.Now()
+		if b.last.IsZero() {
+			b.last = now
+		}
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		if err := clk.Sleep(ctx, wait); err != nil {
+			return fmt.Errorf("waiting for rate limiter: %w", err)
+		}
+	}
+}
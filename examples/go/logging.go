@@ -0,0 +1,105 @@
+// CSV2GEO API - Go Geocoding Example: request logging hooks
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+)
+
+// Logger receives a callback around every HTTP round trip, including each
+// retry attempt. URLs passed to OnRequest have the API key redacted.
+// OnResponse gets status 0 when no response was received.
+type Logger interface {
+	OnRequest(method, url string)
+	OnResponse(status int, duration time.Duration)
+}
+
+// WithLogger installs a Logger on the client
+func WithLogger(l Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// logRequest reports req to the logger, if any
+func (c *Client) logRequest(req *http.Request) {
+	if c.logger != nil {
+		c.logger.OnRequest(req.Method, c.redact(req.URL.String()))
+	}
+}
+
+// logResponse reports the outcome of a round trip to the logger, if any
+func (c *Client) logResponse(resp *http.Response, start time.Time) {
+	if c.logger == nil {
+		return
+	}
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	c.logger.OnResponse(status, c.since(start))
+}
+
+// WithDebugWriter writes a dump of every HTTP exchange to w, each retry
+// attempt included: the request line, headers and body as sent, then the
+// response status, headers and body. The API key is replaced with *** in
+// headers, URLs and bodies, so the output can be attached to a support
+// ticket. Bodies are buffered for the dump and restored, so requests and
+// responses work as usual. Dumps from concurrent calls are not
+// interleaved. Meant for debugging; it is slow for large batches.
+func WithDebugWriter(w io.Writer) Option {
+	return func(c *Client) {
+		if w == nil {
+			c.debug = nil
+			return
+		}
+		c.debug = &debugWriter{w: w}
+	}
+}
+
+// debugWriter serializes dumps to a WithDebugWriter writer
+type debugWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (d *debugWriter) write(dump string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	io.WriteString(d.w, dump)
+}
+
+// dumpRequest writes req to the debug writer, if any
+func (c *Client) dumpRequest(req *http.Request) {
+	if c.debug == nil {
+		return
+	}
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		c.debug.write(fmt.Sprintf("--> %s %s (dump failed: %v)\n\n", req.Method, c.redact(req.URL.String()), err))
+		return
+	}
+	c.debug.write("--> " + c.redact(string(dump)) + "\n\n")
+}
+
+// dumpResponse writes resp, or the transport error, to the debug writer,
+// if any
+func (c *Client) dumpResponse(resp *http.Response, err error) {
+	if c.debug == nil {
+		return
+	}
+	if err != nil {
+		c.debug.write("<-- " + c.redact(err.Error()) + "\n\n")
+		return
+	}
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		c.debug.write(fmt.Sprintf("<-- %s (dump failed: %v)\n\n", resp.Status, err))
+		return
+	}
+	c.debug.write("<-- " + c.redact(string(dump)) + "\n\n")
+}
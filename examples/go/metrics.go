@@ -0,0 +1,58 @@
+// CSV2GEO API - Go Geocoding Example: request metrics
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Metric names and labels recorded through MetricsRecorder:
+//
+//	csv2geo_requests_total{endpoint, status}      counter
+//	csv2geo_request_duration_seconds{endpoint}    histogram
+//
+// endpoint is the request path (e.g. "/api/v1/geocode") and status the
+// HTTP status code, or "error" when no response was received. Every HTTP
+// attempt is recorded, so retries show up as separate requests.
+const (
+	MetricRequestsTotal   = "csv2geo_requests_total"
+	MetricRequestDuration = "csv2geo_request_duration_seconds"
+)
+
+// MetricsRecorder receives one observation per HTTP attempt. It keeps the
+// client free of a Prometheus dependency; with client_golang, a
+// CounterVec and HistogramVec registered on your prometheus.Registerer
+// satisfy it in a few lines:
+//
+//	type promMetrics struct {
+//		requests *prometheus.CounterVec   // labels: endpoint, status
+//		duration *prometheus.HistogramVec // labels: endpoint
+//	}
+//
+//	func (m promMetrics) ObserveRequest(endpoint, status string, d time.Duration) {
+//		m.requests.WithLabelValues(endpoint, status).Inc()
+//		m.duration.WithLabelValues(endpoint).Observe(d.Seconds())
+//	}
+type MetricsRecorder interface {
+	ObserveRequest(endpoint, status string, duration time.Duration)
+}
+
+// WithMetrics records request counts and latencies to m
+func WithMetrics(m MetricsRecorder) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// recordMetrics reports one HTTP attempt, if metrics are enabled
+func (c *Client) recordMetrics(req *http.Request, resp *http.Response, start time.Time) {
+	if c.metrics == nil {
+		return
+	}
+	status := "error"
+	if resp != nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	c.metrics.ObserveRequest(req.URL.Path, status, c.since(start))
+}
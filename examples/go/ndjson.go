@@ -0,0 +1,73 @@
+// CSV2GEO API - Go Geocoding Example: newline-delimited JSON output
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// NDJSONRecord is one line written by GeocodeCSVToNDJSON. Result is the
+// best result of the row and is absent when Error is set.
+type NDJSONRecord struct {
+	Line   int            `json:"line"` // CSV line number, the header being line 1
+	Query  string         `json:"query"`
+	Result *GeocodeResult `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// GeocodeCSVToNDJSON geocodes a CSV stream to NDJSON using the default
+// client
+func GeocodeCSVToNDJSON(ctx context.Context, r io.Reader, addressColumn string, w io.Writer, opts ...CallOption) error {
+	return defaultClient.GeocodeCSVToNDJSON(ctx, r, addressColumn, w, opts...)
+}
+
+// GeocodeCSVToNDJSON reads a CSV with a header row from r, geocodes
+// addressColumn row by row and writes one NDJSONRecord per row to w as
+// soon as it is geocoded. Nothing is buffered beyond the current row, so a
+// slow reader of w holds up geocoding rather than growing memory. If w has
+// a Flush method (bufio.Writer, http.ResponseWriter, ...) it is called
+// after every line so that downstream consumers see progress. Rows fail
+// individually, as in GeocodeCSV.
+func (c *Client) GeocodeCSVToNDJSON(ctx context.Context, r io.Reader, addressColumn string, w io.Writer, opts ...CallOption) error {
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	flush := flusher(w)
+
+	noHeader := func([]string) error { return nil }
+	writeLine := func(row csvRow) error {
+		rec := NDJSONRecord{Line: row.line, Query: row.query, Result: row.best}
+		if row.err != nil {
+			rec.Error = row.err.Error()
+		}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("failed to write NDJSON for CSV row %d: %w", row.line, err)
+		}
+		if err := flush(); err != nil {
+			return fmt.Errorf("failed to flush NDJSON for CSV row %d: %w", row.line, err)
+		}
+		return nil
+	}
+	_, err = c.geocodeRows(ctx, r, ColumnMapping{Address: addressColumn}, cfg, opts, noHeader, writeLine)
+	return err
+}
+
+// flusher returns a function flushing w, or doing nothing if w can't flush
+func flusher(w io.Writer) func() error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush
+	case http.Flusher:
+		return func() error {
+			f.Flush()
+			return nil
+		}
+	default:
+		return func() error { return nil }
+	}
+}
@@ -0,0 +1,157 @@
+// CSV2GEO API - Go Geocoding Example: address normalization
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NormalizeAddress tidies an address string before geocoding. It is
+// deterministic and deliberately conservative:
+//
+//   - leading/trailing whitespace is trimmed and internal runs of spaces,
+//     tabs and newlines become a single space
+//   - repeated punctuation is collapsed ("St.." -> "St.", "A,, B" -> "A, B")
+//     and empty comma-separated parts, including a trailing comma, are dropped
+//   - parts are rejoined with ", "
+//   - a final part of exactly two letters is taken as a country code and
+//     uppercased ("Paris, fr" -> "Paris, FR"); nothing else changes case
+func NormalizeAddress(s string) string {
+	var parts []string
+	for _, part := range strings.Split(s, ",") {
+		part = collapsePunctuation(strings.Join(strings.Fields(part), " "))
+		part = strings.TrimSpace(part)
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if n := len(parts); n > 1 && isCountryCode(parts[n-1]) {
+		parts[n-1] = strings.ToUpper(parts[n-1])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// collapsePunctuation drops a punctuation rune that repeats the one before it
+func collapsePunctuation(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	var prev rune
+	for _, r := range s {
+		if r == prev && unicode.IsPunct(r) {
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String()
+}
+
+// isCountryCode reports whether s looks like an ISO alpha-2 code
+func isCountryCode(s string) bool {
+	return len(s) == 2 && isASCIILetter(s[0]) && isASCIILetter(s[1])
+}
+
+// addressRange matches a leading house-number range such as "100-120" or
+// "7a – 9a", with any common dash between the numbers, followed by the rest
+// of the address
+var addressRange = regexp.MustCompile(`^\s*(\d+)([A-Za-z]?)\s*[-\x{2010}-\x{2015}\x{2212}]\s*(\d+)([A-Za-z]?)(\s+\S.*)$`)
+
+// SplitAddressRange detects an address starting with a house-number range,
+// such as "100-120 Main St", and returns the addresses of both ends ("100
+// Main St" and "120 Main St"), so that callers can geocode each and
+// interpolate between them. The numbers may be separated by a hyphen, en
+// or em dash or minus sign, with or without spaces. ok is false when s has
+// no range, and also when the second number is not greater than the first:
+// "37-12 82nd St" is a single hyphenated house number, as used in Queens,
+// not a range.
+func SplitAddressRange(s string) (low, high string, ok bool) {
+	m := addressRange.FindStringSubmatch(s)
+	if m == nil {
+		return "", "", false
+	}
+	from, err1 := strconv.Atoi(m[1])
+	to, err2 := strconv.Atoi(m[3])
+	if err1 != nil || err2 != nil || to <= from {
+		return "", "", false
+	}
+	rest := strings.TrimRightFunc(m[5], unicode.IsSpace)
+	return m[1] + m[2] + rest, m[3] + m[4] + rest, true
+}
+
+var (
+	// leadingHouseNumber splits "1600 Pennsylvania Ave" into number and street
+	leadingHouseNumber = regexp.MustCompile(`^(\d+[A-Za-z]?(?:-\d+)?)\s+(\S.*)$`)
+	// stateAndPostcode matches "DC 20500" or "NSW 2000"
+	stateAndPostcode = regexp.MustCompile(`^([A-Z]{2,3})\s+(\d{4,5}(?:-\d{4})?)$`)
+	// postcodeAndCity matches "75008 Paris" or "10115 Berlin"
+	postcodeAndCity = regexp.MustCompile(`^(\d{4,5})\s+(\D+)$`)
+	// postcodeOnly matches a bare numeric postcode, e.g. "20500-0003"
+	postcodeOnly = regexp.MustCompile(`^\d{4,5}(?:-\d{4})?$`)
+	// stateAbbrev matches a state or province code such as "IL" or "NSW"
+	stateAbbrev = regexp.MustCompile(`^[A-Z]{2,3}$`)
+)
+
+// parseFormattedAddress makes a best-effort split of a one-line,
+// comma-separated address into components. It only recognizes a few
+// common shapes and leaves a field empty rather than guess:
+//
+//   - a first part starting with a number is the house number and street
+//   - a final part without digits is the country when at least three parts
+//     follow the street, or when the part before it has a postcode
+//   - the last remaining part may be "ST 12345", "12345 City", a bare
+//     numeric postcode, or (with a part before it) a state code
+//   - the part before that, or the only one left, is the city, if it has
+//     no digits
+//
+// A single part that is not a street address yields nothing, so names
+// like "Eiffel Tower" are not mistaken for a city.
+func parseFormattedAddress(s string) AddressComponents {
+	var c AddressComponents
+	var parts []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return c
+	}
+	rest := parts
+	if m := leadingHouseNumber.FindStringSubmatch(parts[0]); m != nil {
+		c.HouseNumber, c.Street = m[1], m[2]
+		rest = parts[1:]
+	} else if len(parts) < 2 {
+		return c
+	}
+
+	if n := len(rest); n >= 2 && !hasDigit(rest[n-1]) && (n >= 3 || hasDigit(rest[n-2])) {
+		c.Country = rest[n-1]
+		rest = rest[:n-1]
+	}
+	if n := len(rest); n > 0 {
+		last := rest[n-1]
+		if m := stateAndPostcode.FindStringSubmatch(last); m != nil {
+			c.State, c.Postcode = m[1], m[2]
+			rest = rest[:n-1]
+		} else if m := postcodeAndCity.FindStringSubmatch(last); m != nil {
+			c.Postcode, c.City = m[1], strings.TrimSpace(m[2])
+			rest = rest[:n-1]
+		} else if postcodeOnly.MatchString(last) {
+			c.Postcode = last
+			rest = rest[:n-1]
+		} else if n >= 2 && stateAbbrev.MatchString(last) {
+			c.State = last
+			rest = rest[:n-1]
+		}
+	}
+	if n := len(rest); c.City == "" && n > 0 && !hasDigit(rest[n-1]) {
+		c.City = rest[n-1]
+	}
+	return c
+}
+
+func hasDigit(s string) bool {
+	return strings.ContainsAny(s, "0123456789")
+}
@@ -0,0 +1,87 @@
+package main
+
+import "testing"
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  1600   Pennsylvania Ave  ", "1600 Pennsylvania Ave"},
+		{"1600\tPennsylvania\t\tAve,\tWashington", "1600 Pennsylvania Ave, Washington"},
+		{"350 Fifth Ave\nNew York", "350 Fifth Ave New York"},
+		{"350 Fifth Ave, New York,", "350 Fifth Ave, New York"},
+		{"350 Fifth Ave, New York, , ", "350 Fifth Ave, New York"},
+		{"10 Downing St.., London,, uk", "10 Downing St., London, UK"},
+		{"1 Infinite Loop , Cupertino ,CA", "1 Infinite Loop, Cupertino, CA"},
+		{"Rue de Rivoli, Paris, fr", "Rue de Rivoli, Paris, FR"},
+		{"Main St, Springfield, ill", "Main St, Springfield, ill"},
+		{"ny", "ny"},
+		{"", ""},
+		{" , ,", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeAddress(tt.in); got != tt.want {
+			t.Errorf("NormalizeAddress(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAddressIdempotent(t *testing.T) {
+	in := " 12  Main St.. ,, Springfield ,us, "
+	once := NormalizeAddress(in)
+	if twice := NormalizeAddress(once); twice != once {
+		t.Errorf("not idempotent: %q -> %q", once, twice)
+	}
+}
+
+func TestSplitAddressRange(t *testing.T) {
+	tests := []struct {
+		in, low, high string
+		ok            bool
+	}{
+		{"100-120 Main St", "100 Main St", "120 Main St", true},
+		{"100 - 120 Main St, Springfield", "100 Main St, Springfield", "120 Main St, Springfield", true},
+		{"100–120 Main St", "100 Main St", "120 Main St", true},
+		{"100—120 Main St", "100 Main St", "120 Main St", true},
+		{"  7a-9a High Rd  ", "7a High Rd", "9a High Rd", true},
+		{"37-12 82nd St", "", "", false},
+		{"120-120 Main St", "", "", false},
+		{"100 Main St", "", "", false},
+		{"100-120", "", "", false},
+		{"Route 9-11", "", "", false},
+	}
+	for _, tt := range tests {
+		low, high, ok := SplitAddressRange(tt.in)
+		if low != tt.low || high != tt.high || ok != tt.ok {
+			t.Errorf("SplitAddressRange(%q) = %q, %q, %v; want %q, %q, %v", tt.in, low, high, ok, tt.low, tt.high, tt.ok)
+		}
+	}
+}
+
+func TestEnsureComponents(t *testing.T) {
+	tests := []struct {
+		in   string
+		want AddressComponents
+	}{
+		{"1600 Pennsylvania Ave NW, Washington, DC 20500, USA",
+			AddressComponents{HouseNumber: "1600", Street: "Pennsylvania Ave NW", City: "Washington", State: "DC", Postcode: "20500", Country: "USA"}},
+		{"55 Rue du Faubourg Saint-Honoré, 75008 Paris, France",
+			AddressComponents{HouseNumber: "55", Street: "Rue du Faubourg Saint-Honoré", City: "Paris", Postcode: "75008", Country: "France"}},
+		{"Springfield, IL", AddressComponents{City: "Springfield", State: "IL"}},
+		{"Eiffel Tower, Paris, France", AddressComponents{City: "Paris", Country: "France"}},
+		{"10 Downing St, London SW1A 2AA, UK", AddressComponents{HouseNumber: "10", Street: "Downing St", Country: "UK"}},
+		{"Eiffel Tower", AddressComponents{}},
+		{"", AddressComponents{}},
+	}
+	for _, tt := range tests {
+		r := GeocodeResult{FormattedAddress: tt.in}
+		if got := r.EnsureComponents(); got != tt.want {
+			t.Errorf("EnsureComponents(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	r := GeocodeResult{FormattedAddress: "1 Main St, Springfield, IL", Components: AddressComponents{City: "Shelbyville"}}
+	if got := r.EnsureComponents(); got != r.Components {
+		t.Errorf("EnsureComponents() = %+v, want the API's %+v", got, r.Components)
+	}
+}
@@ -0,0 +1,481 @@
+// CSV2GEO API - Go Geocoding Example: per-request options
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// CallOption configures a single request. Unlike Option it never changes
+// the Client, so different calls can use different settings.
+type CallOption func(*callConfig) error
+
+// callConfig collects the effect of the CallOptions passed to one call
+type callConfig struct {
+	params  url.Values
+	header  http.Header
+	timeout time.Duration
+
+	pageSize int // set by WithPage
+
+	progress func(done, total int)
+
+	delimiter  rune // CSV field separator, 0 means comma
+	lazyQuotes bool
+	startRow   int // data rows to skip, set by WithStartRow
+
+	dedup      bool
+	normalizer func(string) string
+	failFast   bool // set by WithFailFast
+
+	idempotencyKey string
+
+	post bool // set by WithPostMethod
+
+	radius int // metres, set by WithRadius; reverse geocoding only
+}
+
+// newCallConfig applies opts in order, stopping at the first invalid one
+func newCallConfig(opts []CallOption) (*callConfig, error) {
+	cfg := &callConfig{params: url.Values{}, header: http.Header{}}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// applyHeaders copies the per-call headers onto req, replacing any of
+// the same name already there
+func (cfg *callConfig) applyHeaders(req *http.Request) {
+	for k, vs := range cfg.header {
+		req.Header[k] = append([]string(nil), vs...)
+	}
+}
+
+// withDeadline applies WithTimeout to ctx. The caller must call the
+// returned cancel func.
+func (cfg *callConfig) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if cfg.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, cfg.timeout)
+}
+
+// formatCoord renders a coordinate without trailing zeros
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// WithViewport biases a forward geocode toward the box spanned by its
+// south-west and north-east corners. It is sent as
+// bounds=minLng,minLat,maxLng,maxLat.
+func WithViewport(sw, ne Location) CallOption {
+	return func(cfg *callConfig) error {
+		if sw.Lat >= ne.Lat || sw.Lng >= ne.Lng {
+			return errors.New("viewport south-west corner must be south and west of north-east corner")
+		}
+		cfg.params.Set("bounds", formatCoord(sw.Lng)+","+formatCoord(sw.Lat)+","+
+			formatCoord(ne.Lng)+","+formatCoord(ne.Lat))
+		return nil
+	}
+}
+
+// WithCountry restricts a forward geocode to one country, given as an
+// ISO 3166-1 alpha-2 code such as "us" or "DE". The code is uppercased.
+func WithCountry(code string) CallOption {
+	return func(cfg *callConfig) error {
+		if len(code) != 2 || !isASCIILetter(code[0]) || !isASCIILetter(code[1]) {
+			return fmt.Errorf("invalid country code %q: want two letters (ISO 3166-1 alpha-2)", code)
+		}
+		cfg.params.Set("country", strings.ToUpper(code))
+		return nil
+	}
+}
+
+// regions are the region codes accepted by WithRegion: the two-letter
+// continent codes
+var regions = map[string]bool{
+	"af": true, // Africa
+	"an": true, // Antarctica
+	"as": true, // Asia
+	"eu": true, // Europe
+	"na": true, // North America
+	"oc": true, // Oceania
+	"sa": true, // South America
+}
+
+// WithRegion biases a forward geocode toward a continent, given as one of
+// "af", "an", "as", "eu", "na", "oc" or "sa" in either case; other values
+// fail the call. It is coarser than WithCountry and useful for names
+// common to several continents, such as "Paris" or "Cambridge". It can be
+// combined with WithCountry and WithViewport, which narrow it further.
+func WithRegion(region string) CallOption {
+	return func(cfg *callConfig) error {
+		r := strings.ToLower(region)
+		if !regions[r] {
+			return fmt.Errorf("unknown region %q: want af, an, as, eu, na, oc or sa", region)
+		}
+		cfg.params.Set("region", r)
+		return nil
+	}
+}
+
+// QueryType tells the server what kind of place a forward query names, to
+// steer ambiguous input such as "Lincoln" or "10001" toward the right kind
+// of match; see WithType
+type QueryType string
+
+const (
+	// TypeAddress is a street address with a house number
+	TypeAddress QueryType = "address"
+	// TypePOI is a named point of interest, such as a landmark or business
+	TypePOI QueryType = "poi"
+	// TypePostcode is a bare postal code
+	TypePostcode QueryType = "postcode"
+	// TypeCity is a city, town or village name
+	TypeCity QueryType = "city"
+)
+
+// WithType sends t as the type parameter of a forward geocode. Values
+// other than the Type constants fail the call.
+func WithType(t QueryType) CallOption {
+	return func(cfg *callConfig) error {
+		switch t {
+		case TypeAddress, TypePOI, TypePostcode, TypeCity:
+		default:
+			return fmt.Errorf("unknown query type %q: want address, poi, postcode or city", string(t))
+		}
+		cfg.params.Set("type", string(t))
+		return nil
+	}
+}
+
+func isASCIILetter(b byte) bool {
+	return ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+// languageTag matches the basic shape of a BCP 47 tag: a 2-3 letter
+// primary language followed by optional subtags, e.g. "ja" or "pt-BR"
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// WithLanguage asks for address text in the given BCP 47 language, e.g.
+// "ja" or "zh-Hant". It sends both a lang parameter and an Accept-Language
+// header, and works for forward and reverse geocoding.
+func WithLanguage(tag string) CallOption {
+	return func(cfg *callConfig) error {
+		if !languageTag.MatchString(tag) {
+			return fmt.Errorf("invalid language tag %q", tag)
+		}
+		cfg.params.Set("lang", tag)
+		cfg.header.Set("Accept-Language", tag)
+		return nil
+	}
+}
+
+// WithLimit caps the number of candidates returned, between 1 and 50. When
+// it is not passed the server's default applies.
+func WithLimit(n int) CallOption {
+	return func(cfg *callConfig) error {
+		if n < 1 || n > 50 {
+			return fmt.Errorf("invalid limit %d: must be between 1 and 50", n)
+		}
+		cfg.params.Set("limit", strconv.Itoa(n))
+		return nil
+	}
+}
+
+// maxRadius is the widest reverse-search radius the API honours, in metres
+const maxRadius = 1500
+
+// WithRadius sets the reverse-search radius in metres, rounded to the
+// nearest metre, up to 1500. Wider radii find addresses for rural points
+// at a lower accuracy_score; the server default is 100. It applies only to
+// ReverseGeocode and BatchReverseGeocode and is ignored by forward geocodes.
+func WithRadius(meters float64) CallOption {
+	return func(cfg *callConfig) error {
+		if math.IsNaN(meters) || meters <= 0 || meters > maxRadius {
+			return fmt.Errorf("invalid radius %v: must be above 0 and at most %d metres", meters, maxRadius)
+		}
+		cfg.radius = int(math.Max(1, math.Round(meters)))
+		return nil
+	}
+}
+
+// applyRadius adds the WithRadius parameter, for reverse requests
+func (cfg *callConfig) applyRadius() {
+	if cfg.radius > 0 {
+		cfg.params.Set("radius", strconv.Itoa(cfg.radius))
+	}
+}
+
+// WithTimeout bounds a single call, including any retries, to d. It is
+// layered on the context passed to the call, so whichever deadline comes
+// first wins: a 2s WithTimeout under a context expiring in 1s still ends
+// after 1s. It is independent of the http.Client Timeout, which applies to
+// each HTTP attempt separately.
+func WithTimeout(d time.Duration) CallOption {
+	return func(cfg *callConfig) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid timeout %v: must be positive", d)
+		}
+		cfg.timeout = d
+		return nil
+	}
+}
+
+// WithPage requests one page of candidates, numbered from 1, of pageSize
+// results each
+func WithPage(page, pageSize int) CallOption {
+	return func(cfg *callConfig) error {
+		if page < 1 {
+			return fmt.Errorf("invalid page %d: pages are numbered from 1", page)
+		}
+		if pageSize < 1 {
+			return fmt.Errorf("invalid page size %d: must be positive", pageSize)
+		}
+		cfg.params.Set("page", strconv.Itoa(page))
+		cfg.params.Set("page_size", strconv.Itoa(pageSize))
+		cfg.pageSize = pageSize
+		return nil
+	}
+}
+
+// WithProgress has BatchGeocodeConcurrent and GeocodeCSV call fn after each
+// address completes. Calls are serialized, so fn needs no locking of its
+// own even when requests run concurrently; it should return quickly.
+func WithProgress(fn func(done, total int)) CallOption {
+	return func(cfg *callConfig) error {
+		cfg.progress = fn
+		return nil
+	}
+}
+
+// progress serializes WithProgress callbacks
+type progress struct {
+	mu    sync.Mutex
+	fn    func(done, total int)
+	done  int
+	total int
+}
+
+func newProgress(fn func(done, total int), total int) *progress {
+	return &progress{fn: fn, total: total}
+}
+
+// step records one completed address and reports it
+func (p *progress) step() {
+	if p.fn == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.fn(p.done, p.total)
+}
+
+// WithDelimiter sets the field separator for CSV geocoding input and
+// output, e.g. '\t' for TSV. The default is a comma. Newlines, quotes and
+// the Unicode replacement character are rejected.
+func WithDelimiter(r rune) CallOption {
+	return func(cfg *callConfig) error {
+		if r == 0 || r == '"' || r == '\r' || r == '\n' || !utf8.ValidRune(r) || r == utf8.RuneError {
+			return fmt.Errorf("invalid CSV delimiter %q", r)
+		}
+		cfg.delimiter = r
+		return nil
+	}
+}
+
+// WithLazyQuotes makes CSV geocoding accept quotes in unquoted fields and
+// non-doubled quotes in quoted fields, as csv.Reader.LazyQuotes does. The
+// output is always written with standard quoting.
+func WithLazyQuotes() CallOption {
+	return func(cfg *callConfig) error {
+		cfg.lazyQuotes = true
+		return nil
+	}
+}
+
+// WithDedup makes BatchGeocodeConcurrent geocode each distinct address only
+// once. Addresses are compared case-insensitively after NormalizeAddress,
+// or with the function given to WithNormalizer. Results still come back in
+// input order, one per original address.
+func WithDedup() CallOption {
+	return func(cfg *callConfig) error {
+		cfg.dedup = true
+		return nil
+	}
+}
+
+// WithFailFast makes BatchGeocodeConcurrent all-or-nothing: the first
+// address that fails cancels the requests in flight, starts no more, and
+// is returned as the error. Without it every address is attempted and
+// failures are only reported per item.
+func WithFailFast() CallOption {
+	return func(cfg *callConfig) error {
+		cfg.failFast = true
+		return nil
+	}
+}
+
+// WithNormalizer replaces the normalization WithDedup uses to decide that
+// two addresses are the same. It has no effect without WithDedup.
+func WithNormalizer(fn func(string) string) CallOption {
+	return func(cfg *callConfig) error {
+		if fn == nil {
+			return errors.New("normalizer must not be nil")
+		}
+		cfg.normalizer = fn
+		return nil
+	}
+}
+
+// WithIdempotencyKey sends key as the Idempotency-Key header of a batch
+// POST, so the server can recognise a retried request and not process or
+// charge it twice. The same key is sent on every retry attempt of the
+// call. When a batch is split into chunks, each chunk gets key suffixed
+// with ":<first index>" since chunks carry different payloads.
+//
+// If retries are enabled (WithRetry) and no key is given, a random UUID is
+// generated per call. The server only deduplicates within its own window,
+// so keys must stay unique per logical request: reusing a key for a
+// different batch after a retry succeeded may return the earlier result.
+func WithIdempotencyKey(key string) CallOption {
+	return func(cfg *callConfig) error {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("idempotency key must not be empty")
+		}
+		cfg.idempotencyKey = key
+		return nil
+	}
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// Result field names accepted by WithFields
+const (
+	FieldFormattedAddress = "formatted_address"
+	FieldLocation         = "location"
+	FieldAccuracy         = "accuracy"
+	FieldConfidence       = "confidence"
+	FieldComponents       = "components"
+)
+
+// WithFields asks the server to return only the named result fields, e.g.
+// WithFields(FieldLocation, FieldAccuracy). Omitted fields decode as their
+// zero value. Names other than the Field constants are passed through for
+// fields this client doesn't know yet.
+func WithFields(fields ...string) CallOption {
+	return func(cfg *callConfig) error {
+		if len(fields) == 0 {
+			return errors.New("WithFields needs at least one field")
+		}
+		for _, f := range fields {
+			if strings.TrimSpace(f) == "" || strings.Contains(f, ",") {
+				return fmt.Errorf("invalid field name %q", f)
+			}
+		}
+		cfg.params.Set("fields", strings.Join(fields, ","))
+		return nil
+	}
+}
+
+// WithPostMethod sends a forward geocode as a JSON POST body instead of a
+// GET with the address in the query string, so that very long addresses
+// don't fail with 414 URI Too Long. The other parameters stay in the URL.
+// It only affects Geocode and GeocodeContext. With WithRetry the POST
+// carries a generated Idempotency-Key unless WithIdempotencyKey set one.
+func WithPostMethod() CallOption {
+	return func(cfg *callConfig) error {
+		cfg.post = true
+		return nil
+	}
+}
+
+// WithParam adds an arbitrary query parameter to the request, for server
+// features this client has no option for yet. It adds rather than replaces,
+// so repeating it with the same key sends several values. Parameters set
+// by other options are kept. The key may not be empty or api_key; use
+// WithAuthMode to send the key in the query string.
+func WithParam(key, value string) CallOption {
+	return func(cfg *callConfig) error {
+		if key == "" {
+			return errors.New("query parameter name must not be empty")
+		}
+		if key == "api_key" {
+			return errors.New("api_key cannot be set with WithParam; use WithAuthMode(AuthQuery)")
+		}
+		cfg.params.Add(key, value)
+		return nil
+	}
+}
+
+// componentFields are the AddressComponents names accepted by
+// WithComponentFields, as they appear in JSON
+var componentFields = map[string]bool{
+	"house_number": true,
+	"street":       true,
+	"city":         true,
+	"state":        true,
+	"postcode":     true,
+	"country":      true,
+}
+
+// WithComponentFields asks the server to return only the named address
+// components, e.g. WithComponentFields("postcode", "country") for a
+// pipeline that must not receive street addresses. Names are the JSON
+// names of AddressComponents; any other name fails the call before a
+// request is made. Omitted components decode as "". It complements
+// WithFields, which selects top-level result fields, and is sent as
+// component_fields.
+func WithComponentFields(fields ...string) CallOption {
+	return func(cfg *callConfig) error {
+		if len(fields) == 0 {
+			return errors.New("WithComponentFields needs at least one field")
+		}
+		for _, f := range fields {
+			if !componentFields[f] {
+				return fmt.Errorf("unknown address component %q", f)
+			}
+		}
+		cfg.params.Set("component_fields", strings.Join(fields, ","))
+		return nil
+	}
+}
+
+// WithStartRow makes the CSV geocoders skip the first n data rows (not
+// counting the header), to resume a run that failed with a *PartialError.
+// When n > 0 GeocodeCSV also leaves out the header row, so the output can
+// be appended to the earlier run's. Skipped rows are not geocoded or
+// counted in Stats.
+func WithStartRow(n int) CallOption {
+	return func(cfg *callConfig) error {
+		if n < 0 {
+			return fmt.Errorf("invalid start row %d: must not be negative", n)
+		}
+		cfg.startRow = n
+		return nil
+	}
+}
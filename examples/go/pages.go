@@ -0,0 +1,63 @@
+// CSV2GEO API - Go Geocoding Example: paginated geocoding
+package main
+
+import (
+	"context"
+	"io"
+)
+
+// GeocodePages pages through the results of query using the default client
+func GeocodePages(ctx context.Context, query string, opts ...CallOption) func() (*GeocodeResponse, error) {
+	return defaultClient.GeocodePages(ctx, query, opts...)
+}
+
+// GeocodePages returns a function that fetches successive pages of results
+// for query, starting at page 1. Pass WithPage to set the page size (its
+// page number is overridden); the default is 10. Each call returns the
+// next page; after the last one it returns io.EOF. A request error leaves
+// the position unchanged, so calling again retries the same page. Paging
+// stops when the server reports HasMore=false, or when a page comes back
+// shorter than the page size or empty, so a short final page is returned
+// and then io.EOF.
+//
+//	next := client.GeocodePages(ctx, "Springfield", WithPage(1, 20))
+//	for {
+//		page, err := next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+func (c *Client) GeocodePages(ctx context.Context, query string, opts ...CallOption) func() (*GeocodeResponse, error) {
+	page := 1
+	done := false
+	return func() (*GeocodeResponse, error) {
+		if done {
+			return nil, io.EOF
+		}
+		cfg, err := newCallConfig(opts)
+		if err != nil {
+			done = true
+			return nil, err
+		}
+		pageSize := cfg.pageSize
+		if pageSize == 0 {
+			pageSize = 10
+		}
+
+		pageOpts := append(append([]CallOption{}, opts...), WithPage(page, pageSize))
+		resp, err := c.GeocodeContext(ctx, query, pageOpts...)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Results) == 0 {
+			done = true
+			return nil, io.EOF
+		}
+		page++
+		if !resp.HasMore || len(resp.Results) < pageSize {
+			done = true
+		}
+		return resp, nil
+	}
+}
@@ -0,0 +1,24 @@
+// CSV2GEO API - Go Geocoding Example: connectivity check
+package main
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Ping checks the default client's connectivity and credentials
+func Ping(ctx context.Context) error {
+	return defaultClient.Ping(ctx)
+}
+
+// Ping verifies that the API is reachable and accepts the client's key,
+// e.g. for a readiness check before a large job. It fetches the account
+// info endpoint, /me, which authenticates the key without geocoding
+// anything, so it does not use lookup quota. (/health is unauthenticated
+// and would not catch a bad key.) A rejected key comes back as *APIError
+// with status 401 or 403; network failures are returned wrapped.
+func (c *Client) Ping(ctx context.Context) error {
+	cfg, _ := newCallConfig(nil)
+	var me json.RawMessage
+	return c.getJSON(ctx, cfg, "/me", "ping failed", &me)
+}
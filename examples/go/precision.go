@@ -0,0 +1,71 @@
+// CSV2GEO API - Go Geocoding Example: output formatting options
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+// defaultCoordinatePrecision is how many decimals output helpers keep:
+// 6 decimals of a degree is about 0.1 m, finer than any geocode
+const defaultCoordinatePrecision = 6
+
+// FormatOption configures a serialization helper such as ToGeoJSON,
+// CSVRecord or Coordinates
+type FormatOption func(*formatConfig)
+
+// formatConfig collects the effect of FormatOptions
+type formatConfig struct {
+	precision int // decimals, or -1 for full precision
+
+	nanPlaceholders bool // set by WithNaNPlaceholders
+}
+
+func newFormatConfig(opts []FormatOption) formatConfig {
+	cfg := formatConfig{precision: defaultCoordinatePrecision}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithCoordinatePrecision rounds latitudes and longitudes in the output to
+// decimals places, 6 by default. Ties round half to even so that rounding
+// many points does not shift them in one direction. A negative decimals
+// keeps full float64 precision.
+func WithCoordinatePrecision(decimals int) FormatOption {
+	return func(cfg *formatConfig) {
+		if decimals < 0 {
+			decimals = -1
+		}
+		cfg.precision = decimals
+	}
+}
+
+// WithNaNPlaceholders makes Coordinates return a [NaN, NaN] pair for a
+// query without results instead of skipping it, so that pair i belongs to
+// query i
+func WithNaNPlaceholders() FormatOption {
+	return func(cfg *formatConfig) {
+		cfg.nanPlaceholders = true
+	}
+}
+
+// round rounds v to the configured precision
+func (cfg formatConfig) round(v float64) float64 {
+	return roundCoord(v, cfg.precision)
+}
+
+// roundCoord rounds v to decimals places, half to even. It goes through
+// strconv, which rounds the exact binary value, instead of scaling by a
+// power of ten, which would add float error of its own.
+func roundCoord(v float64, decimals int) float64 {
+	if decimals < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+	r, err := strconv.ParseFloat(strconv.FormatFloat(v, 'f', decimals, 64), 64)
+	if err != nil {
+		return v
+	}
+	return r
+}
@@ -0,0 +1,54 @@
+// CSV2GEO API - Go Geocoding Example: rate-limit headers
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimit is the quota state reported by the X-RateLimit-* response headers
+type RateLimit struct {
+	Limit     int       // requests allowed per window
+	Remaining int       // requests left in the current window
+	Reset     time.Time // when the window resets; zero if not reported
+}
+
+// LastRateLimit returns the rate-limit headers from the most recent
+// response, successful or not. It is the zero value before any request.
+func (c *Client) LastRateLimit() RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastRateLimit
+}
+
+// recordRateLimit stores the rate-limit headers of resp, if it has any
+func (c *Client) recordRateLimit(resp *http.Response) {
+	rl, ok := parseRateLimit(resp.Header)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	c.lastRateLimit = rl
+	c.mu.Unlock()
+}
+
+// parseRateLimit reads X-RateLimit-Limit, -Remaining and -Reset. Reset is a
+// Unix timestamp in seconds.
+func parseRateLimit(h http.Header) (RateLimit, bool) {
+	var rl RateLimit
+	found := false
+	if n, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil {
+		rl.Limit = n
+		found = true
+	}
+	if n, err := strconv.Atoi(h.Get("X-RateLimit-Remaining")); err == nil {
+		rl.Remaining = n
+		found = true
+	}
+	if secs, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(secs, 0)
+		found = true
+	}
+	return rl, found
+}
@@ -0,0 +1,90 @@
+// CSV2GEO API - Go Geocoding Example: raw responses
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// GeocodeRaw sends the same request as GeocodeContext but returns the live
+// *http.Response without decoding it, for callers that need headers or
+// want to parse the body themselves. Retries, rate limiting, logging and
+// tracing still apply, but the cache does not.
+//
+// Any HTTP status is returned as a response, not as an *APIError; only
+// option, transport and cancellation failures return an error. On success
+// the caller owns the response and must close resp.Body. Closing it also
+// releases the WithTimeout deadline, if one was given. An empty or
+// whitespace-only address returns ErrEmptyQuery without a request.
+func (c *Client) GeocodeRaw(ctx context.Context, address string, opts ...CallOption) (*http.Response, error) {
+	if strings.TrimSpace(address) == "" {
+		return nil, ErrEmptyQuery
+	}
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	cfg.params.Add("q", address)
+
+	ctx, cancel := cfg.withDeadline(ctx)
+	req, err := c.newGetRequest(ctx, cfg, "/geocode")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req, span := c.startSpan(req)
+	resp, t, err := c.send(req)
+	setAttempts(span, t)
+	endSpan(span, nil, err)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// GeocodeWithRaw is GeocodeContext that also returns the response body
+// exactly as the server sent it (after gzip decoding), e.g. for audit logs.
+// The body is read once and decoded from memory, so no second request is
+// made. It bypasses the cache, which does not keep raw bodies.
+func (c *Client) GeocodeWithRaw(ctx context.Context, address string, opts ...CallOption) (*GeocodeResponse, []byte, error) {
+	if strings.TrimSpace(address) == "" {
+		return nil, nil, ErrEmptyQuery
+	}
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.params.Add("q", address)
+
+	ctx, cancel := cfg.withDeadline(ctx)
+	defer cancel()
+	req, err := c.newGetRequest(ctx, cfg, "/geocode")
+	if err != nil {
+		return nil, nil, err
+	}
+	var result GeocodeResponse
+	raw, err := c.doRaw(req, "geocoding failed", &result)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &result, raw, nil
+}
+
+// cancelOnClose releases a request context once its body is closed. What
+// the caller left unread is drained first, so the connection can be
+// reused.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	io.CopyN(io.Discard, b.ReadCloser, maxDrain)
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
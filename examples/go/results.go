@@ -0,0 +1,160 @@
+// CSV2GEO API - Go Geocoding Example: result helpers
+package main
+
+import (
+	"context"
+	"sort"
+)
+
+// AccuracyPrecedence ranks accuracy values from best to worst. Best uses it
+// to pick a result; values not listed rank below all listed ones. Callers
+// may reorder or extend it.
+var AccuracyPrecedence = []Accuracy{
+	AccuracyRooftop,
+	AccuracyInterpolated,
+	AccuracyCenter,
+	AccuracyApproximate,
+}
+
+// accuracyRank returns the position of accuracy in AccuracyPrecedence, or
+// len(AccuracyPrecedence) if it is not listed
+func accuracyRank(accuracy Accuracy) int {
+	for i, a := range AccuracyPrecedence {
+		if a == accuracy {
+			return i
+		}
+	}
+	return len(AccuracyPrecedence)
+}
+
+// Best returns the most accurate result according to AccuracyPrecedence,
+// keeping server order between results of equal accuracy. It returns false
+// if there are no results.
+func (r *GeocodeResponse) Best() (*GeocodeResult, bool) {
+	if r == nil || len(r.Results) == 0 {
+		return nil, false
+	}
+	best := 0
+	for i := 1; i < len(r.Results); i++ {
+		if accuracyRank(r.Results[i].Accuracy) < accuracyRank(r.Results[best].Accuracy) {
+			best = i
+		}
+	}
+	return &r.Results[best], true
+}
+
+// SortByDistance reorders r.Results in place, nearest to from first by
+// DistanceTo. Results at equal distance keep their server order.
+func (r *GeocodeResponse) SortByDistance(from Location) {
+	sort.SliceStable(r.Results, func(i, j int) bool {
+		return r.Results[i].Location.DistanceTo(from) < r.Results[j].Location.DistanceTo(from)
+	})
+}
+
+// SortByConfidence reorders r.Results in place, most confident first.
+// Results of equal confidence keep their server order.
+func (r *GeocodeResponse) SortByConfidence() {
+	sort.SliceStable(r.Results, func(i, j int) bool {
+		return r.Results[i].Confidence > r.Results[j].Confidence
+	})
+}
+
+// EnsureComponents returns r.Components, or, when the API sent none (all
+// fields empty, as in some older responses), a best-effort parse of
+// FormattedAddress. The parse handles common shapes such as "1600
+// Pennsylvania Ave NW, Washington, DC 20500, USA" and leaves any field it
+// cannot place empty; it does not know every country's address format, so
+// treat its output as a hint. r is not modified.
+func (r *GeocodeResult) EnsureComponents() AddressComponents {
+	if r.Components != (AddressComponents{}) {
+		return r.Components
+	}
+	return parseFormattedAddress(r.FormattedAddress)
+}
+
+// MeetsConfidence reports whether the result's Confidence is at least min.
+// A result without a confidence score (0) only meets a min of 0.
+func (r *GeocodeResult) MeetsConfidence(min float64) bool {
+	return r.Confidence >= min
+}
+
+// Filter returns a copy of r holding only the results for which predicate
+// returns true. r itself is not modified, so filters can be chained:
+//
+//	good := resp.Filter(MinAccuracy(AccuracyInterpolated)).Filter(MinConfidence(0.8))
+func (r *GeocodeResponse) Filter(predicate func(GeocodeResult) bool) *GeocodeResponse {
+	out := *r
+	out.Results = make([]GeocodeResult, 0, len(r.Results))
+	for _, res := range r.Results {
+		if predicate(res) {
+			out.Results = append(out.Results, res)
+		}
+	}
+	return &out
+}
+
+// MinAccuracy returns a Filter predicate keeping results at least as
+// accurate as a, according to AccuracyPrecedence
+func MinAccuracy(a Accuracy) func(GeocodeResult) bool {
+	limit := accuracyRank(a)
+	return func(res GeocodeResult) bool {
+		return accuracyRank(res.Accuracy) <= limit
+	}
+}
+
+// MinConfidence returns a Filter predicate keeping results whose
+// Confidence is at least c
+func MinConfidence(c float64) func(GeocodeResult) bool {
+	return func(res GeocodeResult) bool {
+		return res.MeetsConfidence(c)
+	}
+}
+
+// ExcludeInterpolated returns a Filter predicate dropping interpolated
+// results, for uses that need surveyed address points. A result counts as
+// interpolated if the server flags it or its Accuracy is
+// AccuracyInterpolated.
+func ExcludeInterpolated() func(GeocodeResult) bool {
+	return func(res GeocodeResult) bool {
+		return !res.Interpolated && res.Accuracy != AccuracyInterpolated
+	}
+}
+
+// BestResult is Best for callers that prefer an error: it returns
+// ErrNoResults instead of false when there are no results
+func (r *GeocodeResponse) BestResult() (*GeocodeResult, error) {
+	best, ok := r.Best()
+	if !ok {
+		return nil, ErrNoResults
+	}
+	return best, nil
+}
+
+// GeocodeLatLng geocodes address using the default client and returns only the best location
+func GeocodeLatLng(ctx context.Context, address string, opts ...CallOption) (Location, error) {
+	return defaultClient.GeocodeLatLng(ctx, address, opts...)
+}
+
+// GeocodeLatLng geocodes address and returns the location of the Best
+// result, or ErrNoResults if there is none
+func (c *Client) GeocodeLatLng(ctx context.Context, address string, opts ...CallOption) (Location, error) {
+	resp, err := c.GeocodeContext(ctx, address, opts...)
+	if err != nil {
+		return Location{}, err
+	}
+	best, err := resp.BestResult()
+	if err != nil {
+		return Location{}, err
+	}
+	return best.Location, nil
+}
+
+// Equal reports whether a and b describe the same place: same formatted
+// address and accuracy, and locations no more than tolMeters apart by
+// DistanceTo. Confidence and components are not compared, since they may
+// differ between otherwise identical answers.
+func (a GeocodeResult) Equal(b GeocodeResult, tolMeters float64) bool {
+	return a.FormattedAddress == b.FormattedAddress &&
+		a.Accuracy == b.Accuracy &&
+		a.Location.DistanceTo(b.Location) <= tolMeters
+}
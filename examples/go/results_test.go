@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInterpolatedFlag(t *testing.T) {
+	body := `{"query":"100 Main St","results":[
+		{"formatted_address":"100 Main St","accuracy":"rooftop","interpolated":true},
+		{"formatted_address":"100 Main St","accuracy":"rooftop","interpolated":false},
+		{"formatted_address":"100 Main St","accuracy":"rooftop"}
+	]}`
+	var resp GeocodeResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{true, false, false} {
+		if got := resp.Results[i].Interpolated; got != want {
+			t.Errorf("result %d Interpolated = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestExcludeInterpolated(t *testing.T) {
+	resp := &GeocodeResponse{Results: []GeocodeResult{
+		{FormattedAddress: "flagged", Accuracy: AccuracyRooftop, Interpolated: true},
+		{FormattedAddress: "surveyed", Accuracy: AccuracyRooftop},
+		{FormattedAddress: "range", Accuracy: AccuracyInterpolated},
+		{FormattedAddress: "unflagged"},
+	}}
+
+	got := resp.Filter(ExcludeInterpolated())
+
+	var names []string
+	for _, r := range got.Results {
+		names = append(names, r.FormattedAddress)
+	}
+	if len(names) != 2 || names[0] != "surveyed" || names[1] != "unflagged" {
+		t.Errorf("kept %q, want [surveyed unflagged]", names)
+	}
+	if len(resp.Results) != 4 {
+		t.Errorf("Filter modified the original response")
+	}
+}
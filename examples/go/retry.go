@@ -0,0 +1,217 @@
+// CSV2GEO API - Go Geocoding Example: retry with exponential backoff
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WithRetry retries requests that come back with HTTP 429, 502, 503 or 504
+// up to maxRetries times. The delay before retry n is baseDelay*2^(n-1) with
+// jitter, or what WithBackoff says, unless the server sends a Retry-After
+// header, which wins.
+//
+// GETs are always retried. A POST that failed with a 5xx may have been
+// processed anyway, so it is only retried if it carries an Idempotency-Key
+// (see WithIdempotencyKey; batch calls generate one when retries are on);
+// a 429 means the server turned the request away, so POSTs are retried on
+// it regardless.
+func WithRetry(maxRetries int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		if maxRetries < 0 {
+			maxRetries = 0
+		}
+		c.maxRetries = maxRetries
+		c.retryBaseDelay = baseDelay
+	}
+}
+
+// WithRetryBudget bounds the total time one call spends on attempts and
+// backoff to maxTotal. A retry that would start after the budget, or after
+// the request context's deadline if that comes first, is not made: the
+// last response is returned instead, and its *APIError notes how much of
+// the budget was spent. A maxTotal of 0 removes the budget.
+func WithRetryBudget(maxTotal time.Duration) Option {
+	return func(c *Client) {
+		if maxTotal < 0 {
+			maxTotal = 0
+		}
+		c.retryBudget = maxTotal
+	}
+}
+
+// retryBudgetExceeded reports why a retry after delay would overrun the
+// retry budget of a call that started at first, or "" if it would not.
+// Without a budget it always allows the retry.
+func (c *Client) retryBudgetExceeded(req *http.Request, first time.Time, delay time.Duration) string {
+	if c.retryBudget <= 0 {
+		return ""
+	}
+	now := c.clock.Now()
+	spent := now.Sub(first)
+	limit := c.retryBudget
+	bound := "retry budget"
+	if deadline, ok := req.Context().Deadline(); ok && deadline.Sub(now) < limit-spent {
+		limit = spent + deadline.Sub(now)
+		bound = "context deadline"
+	}
+	if spent+delay < limit {
+		return ""
+	}
+	return fmt.Sprintf("retries stopped by %s: %v of %v spent, next retry due in %v",
+		bound, spent.Round(time.Millisecond), limit.Round(time.Millisecond), delay.Round(time.Millisecond))
+}
+
+// shouldRetry reports whether req may be sent again after resp, following
+// the policy described at WithRetry
+func shouldRetry(req *http.Request, resp *http.Response) bool {
+	if !retryableStatus(resp.StatusCode) {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryableStatus reports whether a response status is worth retrying
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// BackoffFunc returns the delay before a retry, numbered from 1. A
+// negative delay is treated as 0.
+type BackoffFunc func(attempt int) time.Duration
+
+// WithBackoff replaces the delay curve of WithRetry, which still sets the
+// number of retries; its baseDelay is then unused. A Retry-After header
+// from the server still wins. Compose strategy from ConstantBackoff,
+// LinearBackoff or ExponentialBackoff and WithJitter, or write one. A nil
+// strategy restores the default, WithJitter(ExponentialBackoff(baseDelay,
+// 0)).
+func WithBackoff(strategy BackoffFunc) Option {
+	return func(c *Client) {
+		c.backoffFunc = strategy
+	}
+}
+
+// ConstantBackoff waits d before every retry
+func ConstantBackoff(d time.Duration) BackoffFunc {
+	return func(int) time.Duration { return d }
+}
+
+// LinearBackoff waits step before the first retry, 2*step before the
+// second and so on, up to max. A max of 0 means no cap.
+func LinearBackoff(step, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		if attempt < 1 {
+			attempt = 1
+		}
+		if step > 0 && time.Duration(attempt) > math.MaxInt64/step {
+			return capDelay(math.MaxInt64, max)
+		}
+		return capDelay(step*time.Duration(attempt), max)
+	}
+}
+
+// ExponentialBackoff waits base before the first retry and doubles the
+// delay for each one after, up to max. A max of 0 means no cap.
+func ExponentialBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		if attempt < 1 {
+			attempt = 1
+		}
+		shift := uint(attempt - 1)
+		if base > 0 && (shift >= 63 || base > math.MaxInt64>>shift) {
+			return capDelay(math.MaxInt64, max)
+		}
+		return capDelay(base<<shift, max)
+	}
+}
+
+// capDelay limits d to max, when max is positive
+func capDelay(d, max time.Duration) time.Duration {
+	if max > 0 && d > max {
+		return max
+	}
+	return d
+}
+
+// WithJitter randomizes the delays of b to spread out retries from many
+// clients: each becomes a random duration between half the delay and the
+// full delay. It wraps a BackoffFunc and is not itself an Option; pass the
+// result to WithBackoff.
+func WithJitter(b BackoffFunc) BackoffFunc {
+	return func(attempt int) time.Duration {
+		d := b(attempt)
+		if d <= 0 {
+			return 0
+		}
+		// Keep at least half the delay and randomize the rest
+		half := d / 2
+		return half + time.Duration(rand.Int63n(int64(d-half)+1))
+	}
+}
+
+// backoff returns how long to wait before the given retry (1-based)
+func (c *Client) backoff(retry int, resp *http.Response) time.Duration {
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), c.clock.Now()); ok {
+		return d
+	}
+	strategy := c.backoffFunc
+	if strategy == nil {
+		strategy = WithJitter(ExponentialBackoff(c.retryBaseDelay, 0))
+	}
+	if d := strategy(retry); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// parseRetryAfter accepts both forms of Retry-After: delta-seconds or an
+// HTTP date, which is measured from now
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, returning early with ctx.Err() if ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock advances only when slept on, recording every sleep
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+func (f *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	f.sleeps = append(f.sleeps, d)
+	f.now = f.now.Add(d)
+	return ctx.Err()
+}
+
+func TestRetryBackoffSequence(t *testing.T) {
+	clk := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	srv, c := NewTestServer(RespondError(http.StatusServiceUnavailable, "unavailable", "try later"),
+		WithRetry(3, 100*time.Millisecond), withClock(clk))
+	defer srv.Close()
+
+	_, err := c.Geocode("1600 Pennsylvania Ave")
+	if !IsServerError(err) {
+		t.Fatalf("want 503 APIError, got %v", err)
+	}
+	if len(clk.sleeps) != 3 {
+		t.Fatalf("slept %d times, want 3: %v", len(clk.sleeps), clk.sleeps)
+	}
+	for i, d := range clk.sleeps {
+		max := 100 * time.Millisecond << uint(i)
+		if d < max/2 || d > max {
+			t.Errorf("retry %d waited %v, want between %v and %v", i+1, d, max/2, max)
+		}
+	}
+}
+
+func TestWithBackoff(t *testing.T) {
+	clk := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	srv, c := NewTestServer(RespondError(http.StatusServiceUnavailable, "unavailable", "try later"),
+		WithRetry(4, time.Hour), WithBackoff(LinearBackoff(100*time.Millisecond, 250*time.Millisecond)), withClock(clk))
+	defer srv.Close()
+
+	if _, err := c.Geocode("1600 Pennsylvania Ave"); !IsServerError(err) {
+		t.Fatalf("want 503 APIError, got %v", err)
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond, 250 * time.Millisecond}
+	if !reflect.DeepEqual(clk.sleeps, want) {
+		t.Errorf("sleeps = %v, want %v", clk.sleeps, want)
+	}
+
+	exp := ExponentialBackoff(time.Second, time.Minute)
+	for attempt, want := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 7: time.Minute, 100: time.Minute} {
+		if got := exp(attempt); got != want {
+			t.Errorf("ExponentialBackoff(1s, 1m)(%d) = %v, want %v", attempt, got, want)
+		}
+		if got := WithJitter(exp)(attempt); got < want/2 || got > want {
+			t.Errorf("WithJitter(...)(%d) = %v, want between %v and %v", attempt, got, want/2, want)
+		}
+	}
+	if got := ConstantBackoff(time.Second)(9); got != time.Second {
+		t.Errorf("ConstantBackoff(1s)(9) = %v", got)
+	}
+}
+
+func TestRetryAfterDateUsesClock(t *testing.T) {
+	clk := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	retryAt := clk.now.Add(7 * time.Second).Format(http.TimeFormat)
+	calls := 0
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", retryAt)
+			RespondError(http.StatusTooManyRequests, "rate_limited", "slow down")(w, r)
+			return
+		}
+		RespondGeocode()(w, r)
+	}), WithRetry(1, time.Millisecond), withClock(clk))
+	defer srv.Close()
+
+	if _, err := c.Geocode("1600 Pennsylvania Ave"); err != nil {
+		t.Fatal(err)
+	}
+	if len(clk.sleeps) != 1 || clk.sleeps[0] != 7*time.Second {
+		t.Errorf("sleeps = %v, want [7s]", clk.sleeps)
+	}
+}
+
+// countingServer answers every request with status, counting requests
+func countingServer(t *testing.T, status int, opts ...Option) (*Client, *int) {
+	calls := 0
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		RespondError(status, "unavailable", "try later")(w, r)
+	}), opts...)
+	t.Cleanup(srv.Close)
+	return c, &calls
+}
+
+func TestRetryPostWithIdempotencyKey(t *testing.T) {
+	c, calls := countingServer(t, http.StatusServiceUnavailable,
+		WithRetry(2, time.Millisecond), withClock(&fakeClock{}))
+
+	_, err := c.BatchGeocode([]string{"a", "b"}, WithIdempotencyKey("job-1"))
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if *calls != 3 {
+		t.Errorf("server saw %d requests, want 3", *calls)
+	}
+}
+
+func TestNoRetryPostWithoutIdempotencyKey(t *testing.T) {
+	c, calls := countingServer(t, http.StatusServiceUnavailable,
+		WithRetry(2, time.Millisecond), withClock(&fakeClock{}))
+
+	cfg, _ := newCallConfig(nil)
+	body := BatchGeocodeRequest{Addresses: []string{"1600 Pennsylvania Ave"}}
+	err := c.postJSON(context.Background(), cfg, "/geocode", body, "geocode failed", &BatchGeocodeResponse{})
+	if !IsServerError(err) {
+		t.Fatalf("want 503 APIError, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("server saw %d requests, want 1: POST without key must not be retried", *calls)
+	}
+}
+
+func TestRetryPostMethodGeneratesKey(t *testing.T) {
+	var keys []string
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		RespondError(http.StatusServiceUnavailable, "unavailable", "try later")(w, r)
+	}), WithRetry(2, time.Millisecond), withClock(&fakeClock{}))
+	defer srv.Close()
+
+	if _, err := c.Geocode("1600 Pennsylvania Ave", WithPostMethod()); !IsServerError(err) {
+		t.Fatalf("want 503 APIError, got %v", err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("server saw %d requests, want 3", len(keys))
+	}
+	if keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Errorf("Idempotency-Key = %q, want one generated key on every attempt", keys)
+	}
+}
+
+func TestRetryPostOnRateLimitWithoutKey(t *testing.T) {
+	c, calls := countingServer(t, http.StatusTooManyRequests,
+		WithRetry(2, time.Millisecond), withClock(&fakeClock{}))
+
+	c.Geocode("1600 Pennsylvania Ave", WithPostMethod())
+	if *calls != 3 {
+		t.Errorf("server saw %d requests, want 3", *calls)
+	}
+}
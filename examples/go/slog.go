@@ -0,0 +1,67 @@
+// CSV2GEO API - Go Geocoding Example: structured logging with log/slog
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// WithSlog logs every HTTP attempt to logger with structured attributes:
+// the start of each attempt at Debug, its result at Debug, or at Warn for
+// an error status, each retry at Info and transport failures at Error.
+// Records carry method, endpoint (the URL path) and attempt, plus status
+// and duration once known; the API key is redacted from every value. It
+// may be used together with WithLogger, and both are called. A nil logger
+// turns it off.
+func WithSlog(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.slog = logger
+	}
+}
+
+// slogAttempt logs the start of attempt n of req
+func (c *Client) slogAttempt(req *http.Request, n int) {
+	if c.slog == nil {
+		return
+	}
+	c.slog.LogAttrs(req.Context(), slog.LevelDebug, "csv2geo request",
+		c.slogRequestAttrs(req, n)...)
+}
+
+// slogResult logs the response to, or transport failure of, attempt n
+func (c *Client) slogResult(req *http.Request, n int, resp *http.Response, err error, duration time.Duration) {
+	if c.slog == nil {
+		return
+	}
+	attrs := append(c.slogRequestAttrs(req, n), slog.Duration("duration", duration))
+	if err != nil {
+		attrs = append(attrs, slog.String("error", c.redact(err.Error())))
+		c.slog.LogAttrs(context.WithoutCancel(req.Context()), slog.LevelError, "csv2geo request failed", attrs...)
+		return
+	}
+	level := slog.LevelDebug
+	if resp.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	c.slog.LogAttrs(req.Context(), level, "csv2geo response", attrs...)
+}
+
+// slogRetry logs that attempt n got status and will be retried after delay
+func (c *Client) slogRetry(req *http.Request, n int, status int, delay time.Duration) {
+	if c.slog == nil {
+		return
+	}
+	attrs := append(c.slogRequestAttrs(req, n), slog.Int("status", status), slog.Duration("delay", delay))
+	c.slog.LogAttrs(req.Context(), slog.LevelInfo, "csv2geo retrying", attrs...)
+}
+
+func (c *Client) slogRequestAttrs(req *http.Request, n int) []slog.Attr {
+	return []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("endpoint", c.redact(req.URL.Path)),
+		slog.Int("attempt", n),
+	}
+}
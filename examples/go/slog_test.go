@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithSlog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	calls := 0
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			RespondError(http.StatusServiceUnavailable, "unavailable", "try later")(w, r)
+			return
+		}
+		RespondGeocode()(w, r)
+	}), WithSlog(logger), WithRetry(1, time.Millisecond), WithAuthMode(AuthQuery), withClock(&fakeClock{now: time.Unix(0, 0)}))
+	defer srv.Close()
+
+	if _, err := c.Geocode("1600 Pennsylvania Ave"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "test_key") {
+		t.Errorf("log leaks the API key:\n%s", buf.String())
+	}
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec struct {
+			Level, Msg, Endpoint string
+			Attempt, Status      int
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("bad record %q: %v", line, err)
+		}
+		if rec.Endpoint != "/v1/geocode" {
+			t.Errorf("endpoint = %q in %s", rec.Endpoint, line)
+		}
+		got = append(got, strings.Join([]string{rec.Level, rec.Msg, strconv.Itoa(rec.Attempt), strconv.Itoa(rec.Status)}, " "))
+	}
+	want := []string{
+		"DEBUG csv2geo request 1 0",
+		"WARN csv2geo response 1 503",
+		"INFO csv2geo retrying 1 503",
+		"DEBUG csv2geo request 2 0",
+		"DEBUG csv2geo response 2 200",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("records:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
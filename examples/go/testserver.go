@@ -0,0 +1,56 @@
+// CSV2GEO API - Go Geocoding Example: test server helpers
+//
+// These would live in a csv2geotest subpackage once this example is an
+// importable module; as a single package main they sit alongside the client.
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+)
+
+// NewTestServer starts an httptest.Server running handler and returns it
+// with a Client already pointed at it, so code that geocodes can be tested
+// without network access. The caller must Close the server.
+func NewTestServer(handler http.Handler, opts ...Option) (*httptest.Server, *Client) {
+	srv := httptest.NewServer(handler)
+	opts = append([]Option{WithBaseURL(srv.URL), WithHTTPClient(srv.Client())}, opts...)
+	return srv, NewClient("test_key", opts...)
+}
+
+// RespondJSON returns a handler that replies with status and v as JSON
+func RespondJSON(status int, v interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// RespondGeocode returns a handler that answers every request with a 200
+// GeocodeResponse holding results, echoing the q parameter as the query
+func RespondGeocode(results ...GeocodeResult) http.HandlerFunc {
+	if results == nil {
+		results = []GeocodeResult{}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		RespondJSON(http.StatusOK, GeocodeResponse{
+			Query:   r.URL.Query().Get("q"),
+			Results: results,
+		})(w, r)
+	}
+}
+
+// RespondError returns a handler that answers with an API error body in
+// the shape documented in openapi.yaml
+func RespondError(status int, code, message string) http.HandlerFunc {
+	body := map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+			"status":  status,
+		},
+	}
+	return RespondJSON(status, body)
+}
@@ -0,0 +1,124 @@
+// CSV2GEO API - Go Geocoding Example: timezone lookup
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// timezoneCachePrecision is the number of decimals coordinates are rounded
+// to for timezone cache keys: about 100 m, far finer than any timezone
+// boundary needs
+const timezoneCachePrecision = 3
+
+// timezoneResponse is the part of TimezoneResponse in openapi.yaml that
+// Timezone uses
+type timezoneResponse struct {
+	Timezone struct {
+		ID string `json:"id"`
+	} `json:"timezone"`
+}
+
+// Timezone looks up the timezone of loc using the default client
+func Timezone(ctx context.Context, loc Location) (string, error) {
+	return defaultClient.Timezone(ctx, loc)
+}
+
+// Timezone returns the IANA name of the timezone at loc, such as
+// "America/New_York", for use with time.LoadLocation. It returns
+// ErrNoResults if the server can't resolve one, e.g. far out at sea. With
+// WithCache, answers are cached for the cache TTL under loc rounded to 3
+// decimals (about 100 m), so nearby points share an entry. They are kept
+// in a small cache of the client's own, not in the Cache given to
+// WithCache, which only ever holds geocode responses.
+func (c *Client) Timezone(ctx context.Context, loc Location) (string, error) {
+	if err := validateCoordinate(loc.Lat, loc.Lng); err != nil {
+		return "", err
+	}
+	key := formatCoord(roundCoord(loc.Lat, timezoneCachePrecision)) + "," +
+		formatCoord(roundCoord(loc.Lng, timezoneCachePrecision))
+	if c.timezones != nil {
+		if tz, ok := c.timezones.get(key, c.clock.Now()); ok {
+			return tz, nil
+		}
+	}
+
+	cfg, _ := newCallConfig(nil)
+	cfg.params.Set("lat", formatCoord(loc.Lat))
+	cfg.params.Set("lng", formatCoord(loc.Lng))
+	var resp timezoneResponse
+	if err := c.getJSON(ctx, cfg, "/timezone", "timezone lookup failed", &resp); err != nil {
+		if IsNotFound(err) {
+			return "", fmt.Errorf("timezone at %s: %w", pointQuery(loc), ErrNoResults)
+		}
+		return "", err
+	}
+	tz := resp.Timezone.ID
+	if tz == "" {
+		return "", fmt.Errorf("timezone at %s: %w", pointQuery(loc), ErrNoResults)
+	}
+
+	if c.timezones != nil {
+		c.timezones.set(key, tz, c.clock.Now(), c.cacheTTL)
+	}
+	return tz, nil
+}
+
+// maxTimezoneEntries bounds the client's timezone cache
+const maxTimezoneEntries = 4096
+
+// timezoneCache maps rounded coordinates to timezone names. It is created
+// by NewClient when WithCache is set.
+type timezoneCache struct {
+	mu      sync.Mutex
+	entries map[string]timezoneEntry
+}
+
+type timezoneEntry struct {
+	name    string
+	expires time.Time // zero means never
+}
+
+func newTimezoneCache() *timezoneCache {
+	return &timezoneCache{entries: make(map[string]timezoneEntry)}
+}
+
+// get returns the unexpired name cached under key
+func (t *timezoneCache) get(key string, now time.Time) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !e.expires.IsZero() && now.After(e.expires) {
+		delete(t.entries, key)
+		return "", false
+	}
+	return e.name, true
+}
+
+// set caches name under key for ttl. When the cache is full, expired
+// entries are dropped first and, if that frees nothing, all of them:
+// timezones are cheap to look up again.
+func (t *timezoneCache) set(key, name string, now time.Time, ttl time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.entries[key]; !ok && len(t.entries) >= maxTimezoneEntries {
+		for k, e := range t.entries {
+			if !e.expires.IsZero() && now.After(e.expires) {
+				delete(t.entries, k)
+			}
+		}
+		if len(t.entries) >= maxTimezoneEntries {
+			clear(t.entries)
+		}
+	}
+	e := timezoneEntry{name: name}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+	t.entries[key] = e
+}
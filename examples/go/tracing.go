@@ -0,0 +1,104 @@
+// CSV2GEO API - Go Geocoding Example: tracing hooks
+//
+// This example has no go.mod, so it cannot import
+// go.opentelemetry.io/otel/trace directly. These interfaces cover the part
+// of the OpenTelemetry API the client uses; an adapter around an otel
+// trace.TracerProvider is a few lines:
+//
+//	type otelProvider struct{ tp trace.TracerProvider }
+//
+//	func (p otelProvider) Tracer(name string) Tracer {
+//		return otelTracer{p.tp.Tracer(name)}
+//	}
+//
+// and likewise for Tracer and Span.
+package main
+
+import (
+	"context"
+	"net/http"
+)
+
+// TracerProvider hands out named Tracers, like otel's trace.TracerProvider
+type TracerProvider interface {
+	Tracer(name string) Tracer
+}
+
+// Tracer starts spans, like otel's trace.Tracer
+type Tracer interface {
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is an in-progress operation, like otel's trace.Span
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// tracerName identifies this client's spans
+const tracerName = "csv2geo-go"
+
+// WithTracerProvider records a csv2geo.geocode span for every API call,
+// GeocodeRaw included, as a child of the span in the call's context. One
+// span covers all of a call's attempts, and csv2geo.attempts says how many
+// were made. Spans carry the endpoint path (never the query string or API
+// key), the HTTP method and the number of results. For GeocodeRaw the span
+// ends when the response headers arrive. Without this option tracing costs
+// nothing.
+func WithTracerProvider(tp TracerProvider) Option {
+	return func(c *Client) {
+		if tp == nil {
+			c.tracer = nil
+			return
+		}
+		c.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// startSpan starts a span for req and returns req bound to the span's
+// context. It returns a nil Span when tracing is off.
+func (c *Client) startSpan(req *http.Request) (*http.Request, Span) {
+	if c.tracer == nil {
+		return req, nil
+	}
+	ctx, span := c.tracer.Start(req.Context(), "csv2geo.geocode")
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("csv2geo.endpoint", req.URL.Path)
+	return req.WithContext(ctx), span
+}
+
+// setAttempts records on span how many attempts send made
+func setAttempts(span Span, t tries) {
+	if span != nil {
+		span.SetAttribute("csv2geo.attempts", t.n)
+	}
+}
+
+// endSpan finishes span with the call's outcome
+func endSpan(span Span, out interface{}, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+	} else if n, ok := resultCount(out); ok {
+		span.SetAttribute("csv2geo.result_count", n)
+	}
+	span.End()
+}
+
+// resultCount counts the results in a decoded response
+func resultCount(out interface{}) (int, bool) {
+	switch v := out.(type) {
+	case *GeocodeResponse:
+		return len(v.Results), true
+	case *BatchGeocodeResponse:
+		n := 0
+		for _, r := range v.Results {
+			n += len(r.Results)
+		}
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,243 @@
+// CSV2GEO API - Go Geocoding Example: request transport
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// newGetRequest builds a GET for path with the call's query parameters
+// and headers
+func (c *Client) newGetRequest(ctx context.Context, cfg *callConfig, path string) (*http.Request, error) {
+	u := c.endpoint(path)
+	if len(cfg.params) > 0 {
+		u += "?" + cfg.params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, c.redactErr(fmt.Errorf("failed to create request: %w", err))
+	}
+	cfg.applyHeaders(req)
+	return req, nil
+}
+
+// getJSON sends a GET to path with the call's query parameters and
+// headers, and decodes the response into out
+func (c *Client) getJSON(ctx context.Context, cfg *callConfig, path, failMsg string, out interface{}) error {
+	ctx, cancel := cfg.withDeadline(ctx)
+	defer cancel()
+
+	req, err := c.newGetRequest(ctx, cfg, path)
+	if err != nil {
+		return err
+	}
+	return c.do(req, failMsg, out)
+}
+
+// postJSON sends body as JSON to path and decodes the response into out
+func (c *Client) postJSON(ctx context.Context, cfg *callConfig, path string, body interface{}, failMsg string, out interface{}) error {
+	ctx, cancel := cfg.withDeadline(ctx)
+	defer cancel()
+
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	u := c.endpoint(path)
+	if len(cfg.params) > 0 {
+		u += "?" + cfg.params.Encode()
+	}
+	gzipped := false
+	if c.compression && len(reqBody) >= gzipMinBody {
+		reqBody, err = gzipBytes(reqBody)
+		if err != nil {
+			return fmt.Errorf("failed to compress request: %w", err)
+		}
+		gzipped = true
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", u, bytes.NewReader(reqBody))
+	if err != nil {
+		return c.redactErr(fmt.Errorf("failed to create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if cfg.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", cfg.idempotencyKey)
+	}
+	cfg.applyHeaders(req)
+
+	return c.do(req, failMsg, out)
+}
+
+// send performs req, retrying retryable statuses when WithRetry is set, and
+// returns the final response with a record of the attempts made. Any
+// status is returned without error; only transport failures and
+// cancellation fail. If the request's context was cancelled or timed out,
+// the returned error wraps ctx.Err().
+func (c *Client) send(req *http.Request) (*http.Response, tries, error) {
+	if c.initErr != nil {
+		return nil, tries{}, c.initErr
+	}
+	if c.closed.Load() {
+		return nil, tries{}, ErrClientClosed
+	}
+	if c.breaker == nil {
+		return c.sendRetrying(req)
+	}
+	trial, err := c.breaker.allow(c.clock.Now())
+	if err != nil {
+		return nil, tries{}, err
+	}
+	resp, t, err := c.sendRetrying(req)
+	c.breaker.done(c.clock.Now(), trial, breakerOutcome(req, resp, err))
+	return resp, t, err
+}
+
+// setIfAbsent sets h[key] to a non-empty value unless it is already set
+func setIfAbsent(h http.Header, key, value string) {
+	if value != "" && h.Get(key) == "" {
+		h.Set(key, value)
+	}
+}
+
+// tries records the attempts send made for one request
+type tries struct {
+	n       int
+	stopped string // why retries ended early, if they did
+}
+
+// sendRetrying implements send without the circuit breaker
+func (c *Client) sendRetrying(req *http.Request) (*http.Response, tries, error) {
+	// Headers already on req came from the call, possibly through
+	// WithReservedHeader, and win over the client's own
+	c.applyDefaultHeaders(req)
+	c.applyAuth(req)
+	setIfAbsent(req.Header, "User-Agent", c.userAgent)
+	if c.compression {
+		setIfAbsent(req.Header, "Accept-Encoding", "gzip")
+	}
+	first := c.clock.Now()
+	var t tries
+	for {
+		t.n++
+		if c.limiter != nil {
+			if err := c.limiter.Wait(req.Context(), c.clock); err != nil {
+				return nil, t, err
+			}
+		}
+		start := c.clock.Now()
+		c.logRequest(req)
+		c.slogAttempt(req, t.n)
+		c.dumpRequest(req)
+		resp, err := c.HTTPClient.Do(req)
+		c.dumpResponse(resp, err)
+		c.logResponse(resp, start)
+		c.slogResult(req, t.n, resp, err, c.since(start))
+		c.recordMetrics(req, resp, start)
+		if err != nil {
+			if ctxErr := req.Context().Err(); ctxErr != nil {
+				return nil, t, fmt.Errorf("request cancelled after %d attempt(s): %w", t.n, ctxErr)
+			}
+			return nil, t, c.redactErr(fmt.Errorf("request failed after %d attempt(s): %w", t.n, err))
+		}
+		c.recordRateLimit(resp)
+		retry := t.n <= c.maxRetries && shouldRetry(req, resp)
+		var delay time.Duration
+		if retry {
+			delay = c.backoff(t.n, resp)
+			if t.stopped = c.retryBudgetExceeded(req, first, delay); t.stopped != "" {
+				retry = false
+			}
+		}
+		if !retry {
+			if c.compression {
+				decodeGzip(resp)
+			}
+			return resp, t, nil
+		}
+
+		c.slogRetry(req, t.n, resp.StatusCode, delay)
+		drainBody(resp.Body)
+		if err := c.clock.Sleep(req.Context(), delay); err != nil {
+			return nil, t, fmt.Errorf("request cancelled after %d attempt(s): %w", t.n, err)
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, t, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+	}
+}
+
+// maxDrain bounds how much of an unread body drainBody discards. Past
+// that, dropping the connection is cheaper than reading on.
+const maxDrain = 64 << 10
+
+// drainBody reads what is left of body, up to maxDrain, and closes it. The
+// Transport only reuses a keep-alive connection whose body was read to
+// EOF, and a decoder can stop short of it, e.g. before trailing
+// whitespace or the end of a gzip stream.
+func drainBody(body io.ReadCloser) {
+	io.CopyN(io.Discard, body, maxDrain)
+	body.Close()
+}
+
+// timed is implemented by response types that report how long they took
+type timed interface {
+	recordTiming(roundTrip, decode time.Duration)
+}
+
+// do sends req and decodes a 2xx response body into out. Other responses
+// come back as *APIError. If out is timed, it gets the round-trip and
+// decode times.
+func (c *Client) do(req *http.Request, failMsg string, out interface{}) error {
+	_, err := c.doRaw(req, failMsg, out)
+	return err
+}
+
+// doRaw is do, also returning the 2xx response body it decoded
+func (c *Client) doRaw(req *http.Request, failMsg string, out interface{}) (_ []byte, err error) {
+	req, span := c.startSpan(req)
+	defer func() { endSpan(span, out, err) }()
+
+	start := c.clock.Now()
+	resp, t, err := c.send(req)
+	setAttempts(span, t)
+	if err != nil {
+		return nil, err
+	}
+	defer drainBody(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, c.newAPIError(failMsg, resp.StatusCode, resp.Header.Get("Content-Type"), body, t)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, fmt.Errorf("request cancelled: %w", ctxErr)
+		}
+		return nil, c.redactErr(fmt.Errorf("failed to read response: %w", err))
+	}
+	roundTrip := c.since(start)
+
+	decodeStart := c.clock.Now()
+	if err := c.decode(body, out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if t, ok := out.(timed); ok {
+		t.recordTiming(roundTrip, c.since(decodeStart))
+	}
+
+	return body, nil
+}
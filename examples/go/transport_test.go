@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchGeocodeDeadlineCancelsInFlight(t *testing.T) {
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Start the body, then stall until the client gives up
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results": [`))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.BatchGeocodeContext(ctx, []string{"1600 Pennsylvania Ave", "10 Downing St"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("returned after %v, want soon after the 50ms deadline", elapsed)
+	}
+}
+
+// BenchmarkConnectionReuse reports how many TCP connections each request
+// opens, against a server whose JSON bodies end in padding a decoder stops
+// short of. Drained bodies keep it at 0 conns/op; a value near 1 means
+// every request is dialling afresh.
+func BenchmarkConnectionReuse(b *testing.B) {
+	body := `{"query": "q", "results": []}` + strings.Repeat(" ", 32<<10)
+	var conns atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+	c := NewClient("test_key", WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+
+	run := func(b *testing.B, geocode func() error) {
+		conns.Store(0)
+		for i := 0; i < b.N; i++ {
+			if err := geocode(); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(conns.Load())/float64(b.N), "conns/op")
+	}
+	b.Run("Geocode", func(b *testing.B) {
+		run(b, func() error {
+			_, err := c.GeocodeContext(context.Background(), "1600 Pennsylvania Ave")
+			return err
+		})
+	})
+	b.Run("GeocodeRaw", func(b *testing.B) {
+		run(b, func() error {
+			resp, err := c.GeocodeRaw(context.Background(), "1600 Pennsylvania Ave")
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			var out GeocodeResponse
+			return json.NewDecoder(resp.Body).Decode(&out)
+		})
+	})
+}
+
+func TestWithAPIVersion(t *testing.T) {
+	var paths, wrapped []string
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/batch") {
+			var body jobCreateRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			wrapped = append(wrapped, body.API)
+			RespondJSON(http.StatusOK, jobCreateResponse{ID: "job_1"})(w, r)
+			return
+		}
+		RespondGeocode()(w, r)
+	}), WithAPIVersion("v2"))
+	defer srv.Close()
+
+	if _, err := c.Geocode("1600 Pennsylvania Ave"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SubmitBulkJob(context.Background(), []string{"1600 Pennsylvania Ave"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/v2/geocode", "/v2/batch"}; strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("paths = %q, want %q", paths, want)
+	}
+	if len(wrapped) != 1 || wrapped[0] != "/v2/geocode" {
+		t.Errorf("job wraps %q, want /v2/geocode", wrapped)
+	}
+	if c := NewClient("k", WithAPIVersion("2")); c.Err() == nil {
+		t.Error(`WithAPIVersion("2") did not set Err`)
+	}
+}
+
+// recordingTracer keeps the attributes of every span it starts
+type recordingTracer struct{ spans []*recordingSpan }
+
+type recordingSpan struct {
+	attrs map[string]interface{}
+	ended bool
+}
+
+func (r *recordingTracer) Tracer(string) Tracer { return r }
+
+func (r *recordingTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	s := &recordingSpan{attrs: map[string]interface{}{}}
+	r.spans = append(r.spans, s)
+	return ctx, s
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *recordingSpan) RecordError(error)                          {}
+func (s *recordingSpan) End()                                       { s.ended = true }
+
+func TestTracingSpansPerCall(t *testing.T) {
+	calls := 0
+	srv, c := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			RespondError(http.StatusServiceUnavailable, "unavailable", "try later")(w, r)
+			return
+		}
+		RespondGeocode()(w, r)
+	}), WithRetry(1, time.Millisecond), withClock(&fakeClock{}))
+	defer srv.Close()
+	tracer := &recordingTracer{}
+	WithTracerProvider(tracer)(c)
+
+	if _, err := c.Geocode("1600 Pennsylvania Ave"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.GeocodeRaw(context.Background(), "1600 Pennsylvania Ave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("%d spans, want one per call", len(tracer.spans))
+	}
+	for i, want := range []int{2, 1} {
+		s := tracer.spans[i]
+		if !s.ended || s.attrs["csv2geo.attempts"] != want {
+			t.Errorf("span %d: ended = %v, attempts = %v, want ended after %d", i, s.ended, s.attrs["csv2geo.attempts"], want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// CSV2GEO API - Go Geocoding Example: CSV dry run
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxValidationSamples caps ValidationReport.Samples
+const maxValidationSamples = 20
+
+// ValidationReport summarizes a CSV checked by ValidateCSV. Rows counts
+// all data rows, problem rows included. Samples holds the first problem
+// rows found, up to 20, in file order.
+type ValidationReport struct {
+	Rows       int
+	Empty      int // rows whose mapped address is empty
+	Duplicates int // rows repeating the address of an earlier row
+	Malformed  int // rows the CSV reader rejected, e.g. for a wrong field count
+	Samples    []RowProblem
+}
+
+// OK reports whether no problems were found
+func (r ValidationReport) OK() bool {
+	return r.Empty == 0 && r.Duplicates == 0 && r.Malformed == 0
+}
+
+// RowProblem describes one problem row of a ValidationReport
+type RowProblem struct {
+	Line    int    // CSV line number, the header being line 1
+	Problem string // "empty address", "duplicate of line N" or the read error
+	Query   string // the assembled address, if the row could be read
+}
+
+// ValidateCSV checks a CSV as GeocodeCSVMapping would read it, without
+// making any API calls, so that problems surface before quota is spent.
+// The header and the mapped and fallback columns must be present;
+// otherwise an error is returned, the same one geocoding would fail with.
+// Every data row is then read and counted, and rows with an empty address,
+// an address repeating an earlier one (compared as by WithDedup) or that
+// fail to parse are reported. Of opts, only WithDelimiter and
+// WithLazyQuotes matter.
+func ValidateCSV(r io.Reader, mapping ColumnMapping, opts ...CallOption) (ValidationReport, error) {
+	var report ValidationReport
+	cfg, err := newCallConfig(opts)
+	if err != nil {
+		return report, err
+	}
+	cr := csv.NewReader(r)
+	if cfg.delimiter != 0 {
+		cr.Comma = cfg.delimiter
+	}
+	cr.LazyQuotes = cfg.lazyQuotes
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return report, errNoCSVHeader
+	}
+	if err != nil {
+		return report, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	idx, err := mapping.resolve(header)
+	if err != nil {
+		return report, err
+	}
+	if _, err := mapping.resolveFallback(header); err != nil {
+		return report, err
+	}
+
+	problem := func(p RowProblem) {
+		if len(report.Samples) < maxValidationSamples {
+			report.Samples = append(report.Samples, p)
+		}
+	}
+	seen := make(map[string]int) // dedup key -> first line
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return report, fmt.Errorf("failed to read CSV row %d: %w", line, err)
+			}
+			report.Rows++
+			report.Malformed++
+			problem(RowProblem{Line: line, Problem: parseErr.Err.Error()})
+			continue
+		}
+		report.Rows++
+
+		query := assembleQuery(record, idx)
+		if query == "" {
+			report.Empty++
+			problem(RowProblem{Line: line, Problem: "empty address"})
+			continue
+		}
+		key := dedupKey(query)
+		if first, dup := seen[key]; dup {
+			report.Duplicates++
+			problem(RowProblem{Line: line, Problem: fmt.Sprintf("duplicate of line %d", first), Query: query})
+			continue
+		}
+		seen[key] = line
+	}
+	return report, nil
+}